@@ -0,0 +1,147 @@
+package ahocorasick
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Boundary selects what must surround a match for it to be reported.
+type Boundary uint8
+
+const (
+	// NoBoundary reports matches regardless of what surrounds them.
+	NoBoundary Boundary = iota
+
+	// ASCIIWordBoundary reports a match only if it is neither preceded nor followed by an ASCII
+	// word character ([0-9A-Za-z_]).
+	ASCIIWordBoundary
+
+	// UnicodeWordBoundary reports a match only if it is neither preceded nor followed by a
+	// Unicode letter, mark, digit or underscore. The input is decoded as UTF-8.
+	UnicodeWordBoundary
+)
+
+// WordBoundary sets the boundary required around matches of all patterns that are not added
+// with a boundary of their own.
+func (tb *TrieBuilder) WordBoundary(b Boundary) *TrieBuilder {
+	tb.boundary = b
+	return tb
+}
+
+// AddPatternBoundary adds a byte pattern whose matches require the boundary b, regardless of
+// the boundary set with WordBoundary.
+func (tb *TrieBuilder) AddPatternBoundary(pattern []byte, b Boundary) *TrieBuilder {
+	if tb.boundaries == nil {
+		tb.boundaries = make(map[uint32]Boundary)
+	}
+	tb.boundaries[tb.numPatterns] = b
+	return tb.AddPattern(pattern)
+}
+
+// AddStringBoundary adds a string pattern whose matches require the boundary b, regardless of
+// the boundary set with WordBoundary.
+func (tb *TrieBuilder) AddStringBoundary(pattern string, b Boundary) *TrieBuilder {
+	if tb.rejectString(pattern) {
+		return tb
+	}
+	tb.AddPatternBoundary([]byte(pattern), b)
+	tb.strs[len(tb.strs)-1] = true
+	return tb
+}
+
+// patternBoundaries returns the boundary of every pattern, or nil if no pattern requires one.
+func (tb *TrieBuilder) patternBoundaries() []Boundary {
+	if tb.boundary == NoBoundary && len(tb.boundaries) == 0 {
+		return nil
+	}
+
+	boundary := make([]Boundary, tb.numPatterns)
+	for i := range boundary {
+		boundary[i] = tb.boundary
+	}
+	for pattern, b := range tb.boundaries {
+		boundary[pattern] = b
+	}
+	return boundary
+}
+
+// RuneBoundaries makes the Trie only report matches that start and end on UTF-8 rune boundaries
+// of the input, so that a pattern never matches part of a multi-byte character. In addition,
+// string patterns that are not valid UTF-8 are rejected: they keep their pattern number but never
+// match, and the first of them is reported by Err. This also applies to the string patterns
+// added before RuneBoundaries was called.
+func (tb *TrieBuilder) RuneBoundaries() *TrieBuilder {
+	if tb.runes {
+		return tb
+	}
+	tb.runes = true
+
+	rejected := false
+	for id, pattern := range tb.patterns {
+		if tb.strs[id] && pattern != nil && !utf8.Valid(pattern) {
+			tb.invalidString(uint32(id), string(pattern))
+			tb.patterns[id] = nil
+			rejected = true
+		}
+	}
+	if rejected {
+		tb.rebuild()
+	}
+	return tb
+}
+
+// Err returns the first error found in the patterns added so far, if any.
+func (tb *TrieBuilder) Err() error {
+	return tb.err
+}
+
+// constrained reports whether matches must pass accept before they are reported.
+func (tr *Trie) constrained() bool {
+	return tr.boundary != nil || tr.runes
+}
+
+// accept reports whether the match of length n ending at end in input satisfies the constraints
+// of its pattern. The whole input is consulted, so context outside of the searched part counts.
+func (tr *Trie) accept(input []byte, end int, n, pattern uint32) bool {
+	start := end - int(n) + 1
+
+	if tr.runes {
+		if !utf8.RuneStart(input[start]) || end+1 < len(input) && !utf8.RuneStart(input[end+1]) {
+			return false
+		}
+	}
+
+	if tr.boundary == nil {
+		return true
+	}
+
+	switch tr.boundary[pattern] {
+	case ASCIIWordBoundary:
+		if start > 0 && isASCIIWord(input[start-1]) {
+			return false
+		}
+		if end+1 < len(input) && isASCIIWord(input[end+1]) {
+			return false
+		}
+	case UnicodeWordBoundary:
+		if r, _ := utf8.DecodeLastRune(input[:start]); isUnicodeWord(r) {
+			return false
+		}
+		if r, _ := utf8.DecodeRune(input[end+1:]); isUnicodeWord(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isASCIIWord reports whether c is an ASCII word character.
+func isASCIIWord(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_'
+}
+
+// isUnicodeWord reports whether r is a Unicode word character. Invalid UTF-8 (and the edges of
+// the input, where decoding yields utf8.RuneError) counts as a non-word character.
+func isUnicodeWord(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsDigit(r) || r == '_'
+}
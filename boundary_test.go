@@ -0,0 +1,209 @@
+package ahocorasick
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWordBoundary(t *testing.T) {
+	cases := []struct {
+		name     string
+		builder  *TrieBuilder
+		input    string
+		expected []*Match
+	}{
+		{
+			"Readme",
+			NewTrieBuilder().WordBoundary(ASCIIWordBoundary).AddStrings([]string{"or", "amet"}),
+			"Lorem ipsum dolor sit amet, consectetur adipiscing elit or not.",
+			[]*Match{
+				newMatchString(22, 1, "amet"),
+				newMatchString(56, 0, "or"),
+			},
+		},
+		{
+			"PerPattern",
+			NewTrieBuilder().AddStringBoundary("or", ASCIIWordBoundary).AddString("amet"),
+			"Lorem ipsum dolor sit ametist",
+			[]*Match{
+				newMatchString(22, 1, "amet"),
+			},
+		},
+		{
+			"PerPatternOverride",
+			NewTrieBuilder().WordBoundary(ASCIIWordBoundary).AddStringBoundary("or", NoBoundary).AddString("amet"),
+			"Lorem ipsum dolor sit ametist",
+			[]*Match{
+				newMatchString(1, 0, "or"),
+				newMatchString(15, 0, "or"),
+			},
+		},
+		{
+			"ASCIIEdges",
+			NewTrieBuilder().WordBoundary(ASCIIWordBoundary).AddStrings([]string{"ord", "_x"}),
+			"ord ordet_x ordæ",
+			[]*Match{
+				newMatchString(0, 0, "ord"),
+				newMatchString(12, 0, "ord"),
+			},
+		},
+		{
+			"Unicode",
+			NewTrieBuilder().WordBoundary(UnicodeWordBoundary).AddStrings([]string{"ord", "på"}),
+			"ordæ på påske «ord» ord",
+			[]*Match{
+				newMatchString(6, 1, "på"),
+				newMatchString(19, 0, "ord"),
+				newMatchString(25, 0, "ord"),
+			},
+		},
+		{
+			"Suffixes",
+			NewTrieBuilder().WordBoundary(ASCIIWordBoundary).AddStrings([]string{"Corasick", "sick", "ick"}),
+			"Aho-Corasick is sick",
+			[]*Match{
+				newMatchString(4, 0, "Corasick"),
+				newMatchString(16, 1, "sick"),
+			},
+		},
+	}
+
+	for _, c := range cases {
+		tr := c.builder.Build()
+		matches := tr.MatchString(c.input)
+
+		if len(matches) != len(c.expected) {
+			t.Errorf("%s: expected %d matches, got %d: %v", c.name, len(c.expected), len(matches), matches)
+			continue
+		}
+
+		for i := range matches {
+			if !MatchEqual(matches[i], c.expected[i]) {
+				t.Errorf("%s: expected %v, got %v", c.name, c.expected[i], matches[i])
+			}
+		}
+	}
+}
+
+func TestWordBoundaryFindAll(t *testing.T) {
+	tr := NewTrieBuilder().
+		MatchKind(LeftmostLongest).
+		WordBoundary(ASCIIWordBoundary).
+		AddStrings([]string{"new york", "new", "york city"}).
+		Build()
+
+	matches := tr.FindAllString("new yorker, new york city")
+	expected := []*Match{
+		newMatchString(0, 1, "new"),
+		newMatchString(12, 0, "new york"),
+	}
+
+	if len(matches) != len(expected) {
+		t.Fatalf("expected %d matches, got %d: %v", len(expected), len(matches), matches)
+	}
+
+	for i := range matches {
+		if !MatchEqual(matches[i], expected[i]) {
+			t.Errorf("expected %v, got %v", expected[i], matches[i])
+		}
+	}
+}
+
+func TestWordBoundaryEncoding(t *testing.T) {
+	trie := NewTrieBuilder().AddStringBoundary("or", ASCIIWordBoundary).AddString("amet").Build()
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, trie); err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	matches := decoded.MatchString("Lorem ipsum dolor sit amet")
+	if len(matches) != 1 || !MatchEqual(matches[0], newMatchString(22, 1, "amet")) {
+		t.Errorf("expected [{22 1 \"amet\"}], got %v", matches)
+	}
+}
+
+func TestRuneBoundaries(t *testing.T) {
+	patterns := [][]byte{{0xb8}, {0xc3}, []byte("ø"), []byte("l")}
+	input := []byte("øl")
+
+	if n := NewTrieBuilder().AddPatterns(patterns).Build().Count(input); n != 4 {
+		t.Errorf("expected 4 matches without rune boundaries, got %d", n)
+	}
+
+	tr := NewTrieBuilder().RuneBoundaries().AddPatterns(patterns).Build()
+	matches := tr.Match(input)
+	expected := []*Match{
+		newMatchString(0, 2, "ø"),
+		newMatchString(2, 3, "l"),
+	}
+
+	if len(matches) != len(expected) {
+		t.Fatalf("expected %d matches, got %d: %v", len(expected), len(matches), matches)
+	}
+	for i := range matches {
+		if !MatchEqual(matches[i], expected[i]) {
+			t.Errorf("expected %v, got %v", expected[i], matches[i])
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, tr); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := decoded.Count(input); n != 2 {
+		t.Errorf("expected 2 matches after decoding, got %d", n)
+	}
+}
+
+func TestRuneBoundariesInvalidPattern(t *testing.T) {
+	tb := NewTrieBuilder().RuneBoundaries().AddStrings([]string{"\xffø", "ø"})
+	if tb.Err() == nil {
+		t.Errorf("expected invalid UTF-8 pattern to be reported")
+	}
+
+	matches := tb.Build().MatchString("\xffø")
+	if len(matches) != 1 || !MatchEqual(matches[0], newMatchString(1, 1, "ø")) {
+		t.Errorf("expected [{1 1 \"ø\"}], got %v", matches)
+	}
+
+	if err := NewTrieBuilder().RuneBoundaries().AddString("ø").Err(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	tb = NewTrieBuilder().
+		RuneBoundaries().
+		AddStringBoundary("\xff", NoBoundary).
+		AddStringBoundary("ø", ASCIIWordBoundary)
+	if tb.Err() == nil {
+		t.Errorf("expected invalid UTF-8 pattern with a boundary to be reported")
+	}
+
+	matches = tb.Build().MatchString("\xff ø")
+	if len(matches) != 1 || !MatchEqual(matches[0], newMatchString(2, 1, "ø")) {
+		t.Errorf("expected [{2 1 \"ø\"}], got %v", matches)
+	}
+	// Strings added before RuneBoundaries are checked too, but byte patterns are kept.
+	tb = NewTrieBuilder().
+		AddStrings([]string{"\xffø", "ø"}).
+		AddPattern([]byte("\xff")).
+		RuneBoundaries()
+	if tb.Err() == nil {
+		t.Errorf("expected invalid UTF-8 pattern added before RuneBoundaries to be reported")
+	}
+
+	matches = tb.Build().MatchString("\xffø")
+	expected := []*Match{newMatchString(0, 2, "\xff"), newMatchString(1, 1, "ø")}
+	if !equalMatches(matches, expected) {
+		t.Errorf("expected %v, got %v", expected, matches)
+	}
+}
@@ -3,9 +3,10 @@ package ahocorasick
 import (
 	"bufio"
 	"encoding/hex"
+	"fmt"
 	"os"
 	"strings"
-	"sync"
+	"unicode/utf8"
 )
 
 // state represents a node in the Aho-Corasick trie during construction.
@@ -28,6 +29,18 @@ type TrieBuilder struct {
 	states      []*state // All states in the trie
 	root        *state   // Root state of the trie
 	numPatterns uint32   // Number of patterns added
+	patterns    [][]byte // Patterns added, by number (nil if rejected), to rebuild the trie
+	strs        []bool   // Whether each pattern was added as a string
+	kind        MatchKind
+	foldASCII   bool // Match ASCII letters case-insensitively
+	foldUnicode bool // Match all letters case-insensitively using Unicode simple folding
+
+	boundary   Boundary            // Boundary required around matches by default
+	boundaries map[uint32]Boundary // Boundaries of patterns added with their own
+	runes      bool                // Only match on UTF-8 rune boundaries
+	endMode    EndMode
+
+	err error // First error found in the patterns
 }
 
 // NewTrieBuilder creates and initializes a new TrieBuilder.
@@ -67,12 +80,31 @@ func (tb *TrieBuilder) addState(value byte, parent *state) *state {
 // for the pattern in the trie. The final state is marked with the
 // pattern length and assigned a unique pattern number.
 func (tb *TrieBuilder) AddPattern(pattern []byte) *TrieBuilder {
+	tb.patterns = append(tb.patterns, append([]byte{}, pattern...))
+	tb.strs = append(tb.strs, false)
+	tb.insert(pattern, tb.numPatterns)
+	tb.numPatterns++
+	return tb
+}
+
+// insert adds the states for a pattern with the given number to the trie.
+func (tb *TrieBuilder) insert(pattern []byte, id uint32) {
+	if tb.foldUnicode {
+		for _, end := range tb.addFolded(tb.root, pattern, 0) {
+			markEnd(end.s, end.n, id)
+		}
+		return
+	}
+
 	s := tb.root
 	var t *state
 	var ok bool
 
 	// Follow/create the path for this pattern.
 	for _, c := range pattern {
+		if tb.foldASCII {
+			c = toLowerASCII(c)
+		}
 		if t, ok = s.trans[c]; !ok {
 			t = tb.addState(c, s)
 			s.trans[c] = t
@@ -81,11 +113,35 @@ func (tb *TrieBuilder) AddPattern(pattern []byte) *TrieBuilder {
 	}
 
 	// Mark the final state with pattern info.
-	s.dict = uint32(len(pattern))
-	s.pattern = tb.numPatterns
-	tb.numPatterns++
+	markEnd(s, uint32(len(pattern)), id)
+}
 
-	return tb
+// rebuild builds the trie again from the patterns added so far, after an option that changes
+// how they are added has been set.
+func (tb *TrieBuilder) rebuild() {
+	if len(tb.patterns) == 0 {
+		return
+	}
+
+	tb.states = tb.states[:0]
+	tb.addState(0, nil) // State 0 (unused)
+	tb.addState(0, nil) // State 1 (root)
+	tb.root = tb.states[1]
+	for id, pattern := range tb.patterns {
+		if pattern != nil {
+			tb.insert(pattern, uint32(id))
+		}
+	}
+}
+
+// markEnd marks s as the end of a pattern of length n. If a pattern already ends at s, the
+// pattern added first keeps the state, so that it wins with LeftmostFirst.
+func markEnd(s *state, n, pattern uint32) {
+	if s.dict != 0 {
+		return
+	}
+	s.dict = n
+	s.pattern = pattern
 }
 
 // AddPatterns adds multiple byte patterns to the Trie.
@@ -98,7 +154,35 @@ func (tb *TrieBuilder) AddPatterns(patterns [][]byte) *TrieBuilder {
 
 // AddString adds a string pattern to the Trie under construction.
 func (tb *TrieBuilder) AddString(pattern string) *TrieBuilder {
-	return tb.AddPattern([]byte(pattern))
+	if tb.rejectString(pattern) {
+		return tb
+	}
+	tb.AddPattern([]byte(pattern))
+	tb.strs[len(tb.strs)-1] = true
+	return tb
+}
+
+// rejectString reports whether a string pattern must be rejected because it is not valid UTF-8
+// while RuneBoundaries is set. A rejected pattern keeps its pattern number and is reported by
+// Err if it is the first.
+func (tb *TrieBuilder) rejectString(pattern string) bool {
+	if !tb.runes || utf8.ValidString(pattern) {
+		return false
+	}
+	tb.invalidString(tb.numPatterns, pattern)
+	// Reserve the pattern number, so that the following patterns keep theirs.
+	tb.patterns = append(tb.patterns, nil)
+	tb.strs = append(tb.strs, true)
+	tb.numPatterns++
+	return true
+}
+
+// invalidString records the error for a string pattern that is not valid UTF-8, if it is the
+// first error.
+func (tb *TrieBuilder) invalidString(id uint32, pattern string) {
+	if tb.err == nil {
+		tb.err = fmt.Errorf("ahocorasick: pattern %d is not valid UTF-8: %q", id, pattern)
+	}
 }
 
 // AddStrings add multiple strings to the Trie.
@@ -109,6 +193,24 @@ func (tb *TrieBuilder) AddStrings(patterns []string) *TrieBuilder {
 	return tb
 }
 
+// MatchKind sets how the non-overlapping search functions (FindAll and friends) of the built
+// Trie resolve overlapping matches. The default is StandardMatch.
+func (tb *TrieBuilder) MatchKind(kind MatchKind) *TrieBuilder {
+	tb.kind = kind
+	return tb
+}
+
+// CaseInsensitive makes the Trie match ASCII letters regardless of case, so that the pattern
+// "Password" also matches "PASSWORD" and "password". Matches still refer to the input as given.
+// It applies to all patterns, including those added before it was called.
+func (tb *TrieBuilder) CaseInsensitive() *TrieBuilder {
+	if !tb.foldASCII {
+		tb.foldASCII = true
+		tb.rebuild()
+	}
+	return tb
+}
+
 // LoadPatterns loads byte patterns from a file. Expects one pattern per line in hexadecimal form.
 // Empty lines are skipped. Returns error if file cannot be opened or if hex decoding fails.
 func (tb *TrieBuilder) LoadPatterns(path string) error {
@@ -135,7 +237,8 @@ func (tb *TrieBuilder) LoadPatterns(path string) error {
 }
 
 // LoadStrings loads string patterns from a file. Expects one pattern per line.
-// Empty lines are skipped. Returns error if file cannot be opened.
+// Empty lines are skipped. Returns error if file cannot be opened, or the error
+// reported by Err if a pattern was rejected.
 func (tb *TrieBuilder) LoadStrings(path string) error {
 	f, err := os.Open(path)
 	if err != nil {
@@ -152,7 +255,10 @@ func (tb *TrieBuilder) LoadStrings(path string) error {
 		}
 	}
 
-	return s.Err()
+	if err := s.Err(); err != nil {
+		return err
+	}
+	return tb.err
 }
 
 // Build constructs the final optimized Trie structure.
@@ -160,7 +266,7 @@ func (tb *TrieBuilder) LoadStrings(path string) error {
 // 1. Computing failure and dictionary links.
 // 2. Converting the state graph into array-based representation.
 // 3. Pre-computing all possible transitions.
-// 4. Setting up object pools for match results.
+// 4. Computing the depth of every state.
 func (tb *TrieBuilder) Build() *Trie {
 	// Compute failure and dictionary links needed for the Aho-Corasick algorithm.
 	tb.computeFailLinks()
@@ -172,16 +278,15 @@ func (tb *TrieBuilder) Build() *Trie {
 
 	// Initialize the array-based trie structure.
 	trie := &Trie{
-		failTrans: make([][256]uint32, numStates),
-		dictLink:  make([]uint32, numStates),
-		dict:      make([]uint32, numStates),
-		pattern:   make([]uint32, numStates),
-		matchPool: sync.Pool{
-			New: func() any { return &[]*Match{} },
-		},
-		matchStructPool: sync.Pool{
-			New: func() any { return new(Match) },
-		},
+		failTrans:   make([][256]uint32, numStates),
+		dictLink:    make([]uint32, numStates),
+		dict:        make([]uint32, numStates),
+		pattern:     make([]uint32, numStates),
+		kind:        tb.kind,
+		boundary:    tb.patternBoundaries(),
+		runes:       tb.runes,
+		endMode:     tb.endMode,
+		numPatterns: tb.numPatterns,
 	}
 
 	// Convert the state graph into arrays.
@@ -197,12 +302,19 @@ func (tb *TrieBuilder) Build() *Trie {
 		if s.dictLink != nil {
 			trie.dictLink[i] = s.dictLink.id
 		}
-		// Pre-compute all possible byte transitions for this state.
+		// Pre-compute all possible byte transitions for this state. When matching
+		// case-insensitively, upper case letters take the same transition as lower case.
 		for b := range 256 {
 			c := byte(b)
-			trie.failTrans[i][c] = tb.computeFailTransition(s, c)
+			if tb.foldASCII {
+				trie.failTrans[i][c] = tb.computeFailTransition(s, toLowerASCII(c))
+			} else {
+				trie.failTrans[i][c] = tb.computeFailTransition(s, c)
+			}
 		}
 	}
+	trie.depth = computeDepths(trie.failTrans)
+	trie.maxLen = longestPattern(trie.dict)
 
 	return trie
 }
@@ -228,7 +340,12 @@ func (tb *TrieBuilder) computeFailLinks() {
 		s := queue[0]
 		queue = queue[1:]
 
-		for _, t := range s.trans {
+		for c, t := range s.trans {
+			// Case variants share the state they lead to, so only handle
+			// each state once, from its parent along its own byte.
+			if t.parent != s || c != t.value {
+				continue
+			}
 			queue = append(queue, t)
 			// Follow failure links until we find a state that has a transition
 			// on the current character, or reach the root.
@@ -263,3 +380,50 @@ func (tb *TrieBuilder) computeDictLinks() {
 		}
 	}
 }
+
+// toLowerASCII maps the ASCII letters A-Z to a-z and leaves all other bytes unchanged.
+func toLowerASCII(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
+
+// computeDepths returns the depth (distance from the root along goto edges) of every state.
+// A failure transition never leads deeper than one level below the current state, so a
+// breadth-first traversal of the pre-computed transitions finds each state at its true depth.
+// This lets a decoded Trie recover the depths without storing them.
+func computeDepths(failTrans [][256]uint32) []uint32 {
+	depth := make([]uint32, len(failTrans))
+	if len(failTrans) <= int(rootState) {
+		return depth
+	}
+
+	seen := make([]bool, len(failTrans))
+	seen[rootState] = true
+	queue := []uint32{rootState}
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+
+		for _, t := range failTrans[s] {
+			if !seen[t] {
+				seen[t] = true
+				depth[t] = depth[s] + 1
+				queue = append(queue, t)
+			}
+		}
+	}
+
+	return depth
+}
+
+// longestPattern returns the length of the longest pattern, given the pattern length of every
+// state.
+func longestPattern(dict []uint32) int {
+	n := uint32(0)
+	for _, d := range dict {
+		n = max(n, d)
+	}
+	return int(n)
+}
@@ -31,6 +31,9 @@ func TestLoadStrings(t *testing.T) {
 		}
 		t.Errorf("expected %d matches, got %d\n", expected, len(ms))
 	}
+	if n := tr.Count(ibsen); n != expected {
+		t.Errorf("expected to count %d matches, got %d\n", expected, n)
+	}
 }
 
 func TestLoadPatterns(t *testing.T) {
@@ -54,3 +57,31 @@ func TestLoadPatterns(t *testing.T) {
 		t.Errorf("expected %d matches, got %d\n", expected, len(ms))
 	}
 }
+
+func TestCaseInsensitive(t *testing.T) {
+	patterns := []string{"Password", "ØL"}
+	expected := []*Match{
+		newMatchString(0, 0, "password"),
+		newMatchString(9, 0, "PASSWORD"),
+		newMatchString(18, 0, "pAsSwOrD"),
+		newMatchString(40, 1, "ØL"),
+	}
+
+	// The patterns added before CaseInsensitive is called are folded too.
+	for _, tb := range []*TrieBuilder{
+		NewTrieBuilder().CaseInsensitive().AddStrings(patterns),
+		NewTrieBuilder().AddStrings(patterns).CaseInsensitive(),
+	} {
+		matches := tb.Build().MatchString("password PASSWORD pAsSwOrD passw0rd øl ØL")
+
+		if len(matches) != len(expected) {
+			t.Fatalf("expected %d matches, got %d: %v", len(expected), len(matches), matches)
+		}
+
+		for i := range matches {
+			if !MatchEqual(expected[i], matches[i]) {
+				t.Errorf("expected %v, got %v", expected[i], matches[i])
+			}
+		}
+	}
+}
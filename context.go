@@ -0,0 +1,83 @@
+package ahocorasick
+
+import (
+	"context"
+)
+
+// contextChunk is the number of bytes searched between checks for cancellation.
+const contextChunk = 64 << 10
+
+// WalkContext is the same as Walk64, but stops and returns ctx.Err() if ctx is done. The context
+// is checked every 64 KiB of input, so a search can be cancelled even if nothing matches.
+func (tr *Trie) WalkContext(ctx context.Context, input []byte, fn WalkFn64) error {
+	walk := func(end int, n, pattern uint32) bool {
+		return fn(int64(end), n, pattern)
+	}
+
+	s := rootState
+	for from := 0; ; from += contextChunk {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if from >= len(input) {
+			return nil
+		}
+
+		var ok bool
+		if s, ok = tr.scan(s, input, from, min(from+contextChunk, len(input)), walk); !ok {
+			return nil
+		}
+	}
+}
+
+// MatchContext is the same as Match, but stops and returns ctx.Err() if ctx is done, along with
+// the matches found until then.
+func (tr *Trie) MatchContext(ctx context.Context, input []byte) ([]*Match, error) {
+	var matches []Match
+	err := tr.WalkContext(ctx, input, func(end int64, n, pattern uint32) bool {
+		pos := end - int64(n) + 1
+		matches = append(matches, Match{pos: pos, pattern: pattern, match: input[pos : end+1]})
+		return true
+	})
+	return pointers(matches), err
+}
+
+// CountContext is the same as Count, but stops and returns ctx.Err() if ctx is done, along with
+// the number of matches found until then.
+func (tr *Trie) CountContext(ctx context.Context, input []byte) (int, error) {
+	count := 0
+	err := tr.WalkContext(ctx, input, func(end int64, n, pattern uint32) bool {
+		count++
+		return true
+	})
+	return count, err
+}
+
+// FindAllContext is the same as FindAll, but stops and returns ctx.Err() if ctx is done, along
+// with the matches found until then.
+func (tr *Trie) FindAllContext(ctx context.Context, input []byte) ([]*Match, error) {
+	var matches []Match
+	from, to := 0, 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return pointers(matches), err
+		}
+
+		// Search the next chunk. A match that is not certain before the end of the chunk is
+		// searched for again, from where it can start, along with the following chunk.
+		to = min(max(to, from)+contextChunk, len(input))
+		for {
+			sp, ok, resume := tr.search(input, from, to, to == len(input))
+			if !ok {
+				from = resume
+				break
+			}
+			matches = append(matches, sp.match(input))
+			from = sp.pos + sp.n
+		}
+
+		if to == len(input) {
+			return pointers(matches), nil
+		}
+	}
+}
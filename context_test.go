@@ -0,0 +1,103 @@
+package ahocorasick
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func contextInput() []byte {
+	r := rand.New(rand.NewSource(42))
+	input := make([]byte, 5*contextChunk)
+	for i := range input {
+		input[i] = "abcd "[r.Intn(5)]
+	}
+
+	// Plant matches across the chunk boundaries.
+	for i := 1; i < 5; i++ {
+		copy(input[i*contextChunk-7:], "abcdabcdabcd")
+	}
+	return input
+}
+
+func TestContext(t *testing.T) {
+	input := contextInput()
+
+	for _, kind := range []MatchKind{StandardMatch, LeftmostLongest, LeftmostFirst} {
+		tr := NewTrieBuilder().
+			MatchKind(kind).
+			AddStrings([]string{"ab", "abcdabcdabcd", "bcd", "d a", "dabc"}).
+			Build()
+
+		matches, err := tr.MatchContext(context.Background(), input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := tr.Match(input); !equalMatches(matches, expected) {
+			t.Errorf("kind %d: expected %d matches, got %d", kind, len(expected), len(matches))
+		}
+
+		n, err := tr.CountContext(context.Background(), input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := tr.Count(input); n != expected {
+			t.Errorf("kind %d: expected to count %d matches, got %d", kind, expected, n)
+		}
+
+		matches, err = tr.FindAllContext(context.Background(), input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := tr.FindAll(input); !equalMatches(matches, expected) {
+			t.Errorf("kind %d: expected %d non-overlapping matches, got %d", kind, len(expected), len(matches))
+		}
+	}
+}
+
+func TestContextCancel(t *testing.T) {
+	input := bytes.Repeat([]byte("x"), 10*contextChunk)
+	tr := NewTrieBuilder().AddString("y").Build()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := tr.WalkContext(ctx, input, func(end int64, n, pattern uint32) bool { return true }); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+	if _, err := tr.FindAllContext(ctx, input); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+
+	// Cancelling during the search stops it within a chunk.
+	input[0] = 'y'
+	ctx, cancel = context.WithCancel(context.Background())
+	matches, err := tr.MatchContext(ctx, input)
+	if err != nil || len(matches) != 1 {
+		t.Fatalf("expected 1 match and no error, got %d and %v", len(matches), err)
+	}
+
+	var last int64
+	err = tr.WalkContext(ctx, append(input, input...), func(end int64, n, pattern uint32) bool {
+		last = end
+		cancel()
+		return true
+	})
+	if !errors.Is(err, context.Canceled) || last != 0 {
+		t.Errorf("expected %v after first match, got %v after %d", context.Canceled, err, last)
+	}
+}
+
+func equalMatches(a, b []*Match) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !MatchEqual(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,41 @@
+package ahocorasick
+
+// EndMode selects which of the patterns that end at the same position are reported by the
+// overlapping search functions (Walk, Match, All, Count and friends). Such patterns are always
+// suffixes of each other.
+type EndMode uint8
+
+const (
+	// AllAtEnd reports every pattern ending at a position.
+	AllAtEnd EndMode = iota
+
+	// LongestAtEnd only reports the longest pattern ending at a position.
+	LongestAtEnd
+
+	// ShortestAtEnd only reports the shortest pattern ending at a position.
+	ShortestAtEnd
+)
+
+// EndMode sets which of the patterns that end at the same position are reported by the
+// overlapping search functions of the built Trie. The default is AllAtEnd.
+func (tb *TrieBuilder) EndMode(mode EndMode) *TrieBuilder {
+	tb.endMode = mode
+	return tb
+}
+
+// atEnd returns the state of the one match ending at end in state s that is reported when the
+// EndMode is not AllAtEnd, or nilState if there is none.
+func (tr *Trie) atEnd(input []byte, end int, s uint32) uint32 {
+	u := tr.firstAccepted(input, end, s)
+	if tr.endMode == LongestAtEnd || u == nilState {
+		return u
+	}
+
+	shortest := u
+	for u = tr.dictLink[u]; u != nilState; u = tr.dictLink[u] {
+		if !tr.constrained() || tr.accept(input, end, tr.dict[u], tr.pattern[u]) {
+			shortest = u
+		}
+	}
+	return shortest
+}
@@ -0,0 +1,109 @@
+package ahocorasick
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEndMode(t *testing.T) {
+	patterns := []string{"Aho-Corasick", "Corasick", "sick", "k", "Aho"}
+	input := "Aho-Corasick"
+
+	cases := []struct {
+		name     string
+		mode     EndMode
+		expected []*Match
+	}{
+		{
+			"All",
+			AllAtEnd,
+			[]*Match{
+				newMatchString(0, 4, "Aho"),
+				newMatchString(0, 0, "Aho-Corasick"),
+				newMatchString(4, 1, "Corasick"),
+				newMatchString(8, 2, "sick"),
+				newMatchString(11, 3, "k"),
+			},
+		},
+		{
+			"Longest",
+			LongestAtEnd,
+			[]*Match{
+				newMatchString(0, 4, "Aho"),
+				newMatchString(0, 0, "Aho-Corasick"),
+			},
+		},
+		{
+			"Shortest",
+			ShortestAtEnd,
+			[]*Match{
+				newMatchString(0, 4, "Aho"),
+				newMatchString(11, 3, "k"),
+			},
+		},
+	}
+
+	for _, c := range cases {
+		tr := NewTrieBuilder().EndMode(c.mode).AddStrings(patterns).Build()
+		matches := tr.MatchString(input)
+
+		if len(matches) != len(c.expected) {
+			t.Errorf("%s: expected %d matches, got %d: %v", c.name, len(c.expected), len(matches), matches)
+			continue
+		}
+		for i := range matches {
+			if !MatchEqual(matches[i], c.expected[i]) {
+				t.Errorf("%s: expected %v, got %v", c.name, c.expected[i], matches[i])
+			}
+		}
+
+		if n := tr.CountString(input); n != len(c.expected) {
+			t.Errorf("%s: expected to count %d matches, got %d", c.name, len(c.expected), n)
+		}
+		counts := make([]uint32, tr.NumPatterns())
+		tr.CountByPatternString(input, counts)
+		for _, m := range c.expected {
+			counts[m.Pattern()]--
+		}
+		for pattern, n := range counts {
+			if n != 0 {
+				t.Errorf("%s: unexpected count for pattern %d", c.name, pattern)
+			}
+		}
+	}
+}
+
+func TestEndModeBoundary(t *testing.T) {
+	// The longest and shortest patterns that pass the boundary check are reported.
+	build := func(mode EndMode) *Trie {
+		return NewTrieBuilder().
+			EndMode(mode).
+			AddStringBoundary("o-Corasick", ASCIIWordBoundary).
+			AddStrings([]string{"Corasick", "sick"}).
+			AddStringBoundary("ck", ASCIIWordBoundary).
+			Build()
+	}
+
+	matches := build(LongestAtEnd).MatchString("Aho-Corasick")
+	if len(matches) != 1 || !MatchEqual(matches[0], newMatchString(4, 1, "Corasick")) {
+		t.Errorf("expected [{4 1 \"Corasick\"}], got %v", matches)
+	}
+
+	matches = build(ShortestAtEnd).MatchString("Aho-Corasick")
+	if len(matches) != 1 || !MatchEqual(matches[0], newMatchString(8, 2, "sick")) {
+		t.Errorf("expected [{8 2 \"sick\"}], got %v", matches)
+	}
+
+	tr := build(LongestAtEnd)
+	var buf bytes.Buffer
+	if err := Encode(&buf, tr); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := decoded.CountString("Aho-Corasick"); n != 1 {
+		t.Errorf("expected 1 match after decoding, got %d", n)
+	}
+}
@@ -0,0 +1,186 @@
+package ahocorasick
+
+// MatchKind selects how the non-overlapping search functions resolve matches that overlap.
+type MatchKind uint8
+
+const (
+	// StandardMatch reports the match that ends first (the longest one if several end at the
+	// same position), and then restarts the search right after it.
+	StandardMatch MatchKind = iota
+
+	// LeftmostLongest reports the match that starts first, preferring the longest pattern
+	// when several patterns start at the same position.
+	LeftmostLongest
+
+	// LeftmostFirst reports the match that starts first, preferring the pattern that was added
+	// to the TrieBuilder first when several patterns start at the same position. This mirrors
+	// the alternation semantics of Perl-style regular expressions.
+	LeftmostFirst
+)
+
+// Kind returns the MatchKind used by the non-overlapping search functions.
+func (tr *Trie) Kind() MatchKind { return tr.kind }
+
+// FindAll returns the non-overlapping matches in input, resolved according to the MatchKind of
+// the Trie. The matches are ordered by position.
+func (tr *Trie) FindAll(input []byte) []*Match {
+	var found []Match
+	for from := 0; ; {
+		sp, ok := tr.find(input, from)
+		if !ok {
+			break
+		}
+		found = append(found, sp.match(input))
+		from = sp.pos + sp.n
+	}
+	return pointers(found)
+}
+
+// FindAllString is the same as FindAll, but for a string input.
+func (tr *Trie) FindAllString(input string) []*Match {
+	return tr.FindAll([]byte(input))
+}
+
+// FindFirst returns the first of the matches returned by FindAll, or nil if there are none. The
+// search stops as soon as the match is certain. With LeftmostLongest or LeftmostFirst, this is
+// the match that starts first; with StandardMatch, it is the match that ends first.
+func (tr *Trie) FindFirst(input []byte) *Match {
+	sp, ok := tr.find(input, 0)
+	if !ok {
+		return nil
+	}
+	m := sp.match(input)
+	return &m
+}
+
+// FindFirstString is the same as FindFirst, but for a string input.
+func (tr *Trie) FindFirstString(input string) *Match {
+	return tr.FindFirst([]byte(input))
+}
+
+// Index returns the position and pattern of the match returned by FindFirst, or -1, -1 if there
+// is none.
+func (tr *Trie) Index(input []byte) (start, pattern int) {
+	sp, ok := tr.find(input, 0)
+	if !ok {
+		return -1, -1
+	}
+	return sp.pos, int(sp.pattern)
+}
+
+// IndexString is the same as Index, but for a string input.
+func (tr *Trie) IndexString(input string) (start, pattern int) {
+	return tr.Index([]byte(input))
+}
+
+// span is the position, length and pattern of a match.
+type span struct {
+	pos     int
+	n       int
+	pattern uint32
+}
+
+// match returns the match in input that sp refers to.
+func (sp span) match(input []byte) Match {
+	return Match{pos: int64(sp.pos), pattern: sp.pattern, match: input[sp.pos : sp.pos+sp.n]}
+}
+
+// find returns the first non-overlapping match in input[from:], restarting the automaton at the
+// root.
+func (tr *Trie) find(input []byte, from int) (span, bool) {
+	sp, ok, _ := tr.search(input, from, len(input), true)
+	return sp, ok
+}
+
+// search returns the first non-overlapping match in input[from:to], restarting the automaton at
+// the root. If final is false, the input may go on past to, so a match is only returned once no
+// later byte can change it. Otherwise ok is false, and resume is the earliest position where a
+// match can still start; searching again from there with more input gives the same result.
+func (tr *Trie) search(input []byte, from, to int, final bool) (sp span, ok bool, resume int) {
+	if tr.kind == StandardMatch {
+		return tr.searchStandard(input, from, to)
+	}
+	return tr.searchLeftmost(input, from, to, final)
+}
+
+// searchStandard returns the first match to end, which is the longest one ending there. Such a
+// match is certain as soon as it is found.
+func (tr *Trie) searchStandard(input []byte, from, to int) (span, bool, int) {
+	failTrans := tr.failTrans
+
+	s := rootState
+	for i := from; i < to; i++ {
+		s = failTrans[s][input[i]]
+
+		if u := tr.firstAccepted(input, i, s); u != nilState {
+			n := int(tr.dict[u])
+			return span{i - n + 1, n, tr.pattern[u]}, true, 0
+		}
+	}
+	return span{}, false, to - int(tr.depth[s])
+}
+
+// searchLeftmost returns the match that starts first. A candidate is kept until the automaton
+// state gets too shallow for any later match to start at or before it; the depth of the current
+// state bounds how far back a match ending later can begin.
+func (tr *Trie) searchLeftmost(input []byte, from, to int, final bool) (span, bool, int) {
+	failTrans := tr.failTrans
+	depth := tr.depth
+
+	var cand span
+	found := false
+
+	s := rootState
+	for i := from; i < to; i++ {
+		s = failTrans[s][input[i]]
+
+		if found && i+1-int(depth[s]) > cand.pos {
+			return cand, true, 0
+		}
+
+		// Only the longest match ending here can start at or before the candidate.
+		u := tr.firstAccepted(input, i, s)
+		if u == nilState {
+			continue
+		}
+
+		n := int(tr.dict[u])
+		pos := i - n + 1
+		if !found || pos < cand.pos || pos == cand.pos && tr.prefer(n, tr.pattern[u], cand.n, cand.pattern) {
+			cand, found = span{pos, n, tr.pattern[u]}, true
+		}
+	}
+
+	if found && final {
+		return cand, true, 0
+	}
+	resume := to - int(depth[s])
+	if found {
+		resume = min(resume, cand.pos)
+	}
+	return span{}, false, resume
+}
+
+// firstAccepted returns the state of the longest accepted match ending at end in state s, or
+// nilState if there is none.
+func (tr *Trie) firstAccepted(input []byte, end int, s uint32) uint32 {
+	u := s
+	if tr.dict[u] == 0 {
+		u = tr.dictLink[s]
+	}
+	if tr.constrained() {
+		for u != nilState && !tr.accept(input, end, tr.dict[u], tr.pattern[u]) {
+			u = tr.dictLink[u]
+		}
+	}
+	return u
+}
+
+// prefer reports whether a match of length n for pattern a beats a match of length m for
+// pattern b starting at the same position.
+func (tr *Trie) prefer(n int, a uint32, m int, b uint32) bool {
+	if tr.kind == LeftmostFirst {
+		return a < b
+	}
+	return n > m
+}
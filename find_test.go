@@ -0,0 +1,222 @@
+package ahocorasick
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFindAll(t *testing.T) {
+	cases := []struct {
+		name     string
+		kind     MatchKind
+		patterns []string
+		input    string
+		expected []*Match
+	}{
+		{
+			"StandardOverlap",
+			StandardMatch,
+			[]string{"ab", "abcd", "bc"},
+			"abcd",
+			[]*Match{
+				newMatchString(0, 0, "ab"),
+			},
+		},
+		{
+			"StandardRestart",
+			StandardMatch,
+			[]string{"a", "ab", "bab", "bc", "bca", "c", "caa"},
+			"abccab",
+			[]*Match{
+				newMatchString(0, 0, "a"),
+				newMatchString(1, 3, "bc"),
+				newMatchString(3, 5, "c"),
+				newMatchString(4, 0, "a"),
+			},
+		},
+		{
+			"LeftmostLongestOverlap",
+			LeftmostLongest,
+			[]string{"ab", "abcd", "bc"},
+			"abcd",
+			[]*Match{
+				newMatchString(0, 1, "abcd"),
+			},
+		},
+		{
+			"LeftmostLongestLaterStart",
+			LeftmostLongest,
+			[]string{"bcd", "abc", "de"},
+			"abcde",
+			[]*Match{
+				newMatchString(0, 1, "abc"),
+				newMatchString(3, 2, "de"),
+			},
+		},
+		{
+			"LeftmostLongestEndsLater",
+			LeftmostLongest,
+			[]string{"bc", "abcdef"},
+			"xabcdefx",
+			[]*Match{
+				newMatchString(1, 1, "abcdef"),
+			},
+		},
+		{
+			"LeftmostLongestFailedExtension",
+			LeftmostLongest,
+			[]string{"abcdef", "ab", "cd"},
+			"abcdex",
+			[]*Match{
+				newMatchString(0, 1, "ab"),
+				newMatchString(2, 2, "cd"),
+			},
+		},
+		{
+			"LeftmostLongestAdjacent",
+			LeftmostLongest,
+			[]string{"or", "amet"},
+			"Lorem ipsum dolor sit amet",
+			[]*Match{
+				newMatchString(1, 0, "or"),
+				newMatchString(15, 0, "or"),
+				newMatchString(22, 1, "amet"),
+			},
+		},
+		{
+			"LeftmostFirstPriority",
+			LeftmostFirst,
+			[]string{"ab", "abcd", "bc"},
+			"abcd",
+			[]*Match{
+				newMatchString(0, 0, "ab"),
+			},
+		},
+		{
+			"LeftmostFirstLongerFirst",
+			LeftmostFirst,
+			[]string{"abcd", "ab", "bc"},
+			"abcd",
+			[]*Match{
+				newMatchString(0, 0, "abcd"),
+			},
+		},
+		{
+			"LeftmostFirstAlternation",
+			LeftmostFirst,
+			[]string{"Sam", "Samwise"},
+			"Samwise",
+			[]*Match{
+				newMatchString(0, 0, "Sam"),
+			},
+		},
+		{
+			"LeftmostFirstLeftmostWins",
+			LeftmostFirst,
+			[]string{"cd", "bcd", "abcde"},
+			"xabcdex",
+			[]*Match{
+				newMatchString(1, 2, "abcde"),
+			},
+		},
+		{
+			"LeftmostFirstDuplicate",
+			LeftmostFirst,
+			[]string{"ab", "a", "ab"},
+			"ab",
+			[]*Match{
+				newMatchString(0, 0, "ab"),
+			},
+		},
+		{
+			"NoMatch",
+			LeftmostLongest,
+			[]string{"Knuth"},
+			"Aho-Corasick",
+			[]*Match{},
+		},
+	}
+
+	for _, c := range cases {
+		tr := NewTrieBuilder().MatchKind(c.kind).AddStrings(c.patterns).Build()
+		matches := tr.FindAllString(c.input)
+
+		if len(matches) != len(c.expected) {
+			t.Errorf("%s: expected %d matches, got %d", c.name, len(c.expected), len(matches))
+			continue
+		}
+
+		for i := range matches {
+			if !MatchEqual(matches[i], c.expected[i]) {
+				t.Errorf("%s: expected %v, got %v", c.name, c.expected[i], matches[i])
+			}
+		}
+	}
+}
+
+func TestFindFirst(t *testing.T) {
+	patterns := []string{"bcd", "abcdef", "cd"}
+	input := "xabcdefx"
+
+	cases := []struct {
+		kind     MatchKind
+		expected *Match
+	}{
+		{StandardMatch, newMatchString(2, 0, "bcd")},
+		{LeftmostLongest, newMatchString(1, 1, "abcdef")},
+		{LeftmostFirst, newMatchString(1, 1, "abcdef")},
+	}
+
+	for _, c := range cases {
+		tr := NewTrieBuilder().MatchKind(c.kind).AddStrings(patterns).Build()
+
+		if m := tr.FindFirstString(input); m == nil || !MatchEqual(m, c.expected) {
+			t.Errorf("kind %d: expected %v, got %v", c.kind, c.expected, m)
+		}
+
+		start, pattern := tr.IndexString(input)
+		if start != int(c.expected.Pos()) || pattern != int(c.expected.Pattern()) {
+			t.Errorf("kind %d: expected %d, %d, got %d, %d",
+				c.kind, c.expected.Pos(), c.expected.Pattern(), start, pattern)
+		}
+
+		if m := tr.FindFirstString("Aho-Corasick"); m != nil {
+			t.Errorf("kind %d: expected no match, got %v", c.kind, m)
+		}
+		if start, pattern := tr.IndexString("Aho-Corasick"); start != -1 || pattern != -1 {
+			t.Errorf("kind %d: expected -1, -1, got %d, %d", c.kind, start, pattern)
+		}
+	}
+
+	// The pattern number used to be missing from MatchFirst.
+	tr := NewTrieBuilder().AddStrings(patterns).Build()
+	if m := tr.MatchFirstString(input); !MatchEqual(m, newMatchString(2, 0, "bcd")) {
+		t.Errorf("expected {2 0 \"bcd\"}, got %v", m)
+	}
+	if m := tr.MatchFirstString("xcd"); !MatchEqual(m, newMatchString(1, 2, "cd")) {
+		t.Errorf("expected {1 2 \"cd\"}, got %v", m)
+	}
+}
+
+func TestMatchKindEncoding(t *testing.T) {
+	trie := NewTrieBuilder().MatchKind(LeftmostLongest).AddStrings([]string{"ab", "abcd"}).Build()
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, trie); err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.Kind() != LeftmostLongest {
+		t.Errorf("expected kind %d, got %d", LeftmostLongest, decoded.Kind())
+	}
+
+	matches := decoded.FindAllString("abcd")
+	if len(matches) != 1 || !MatchEqual(matches[0], newMatchString(0, 1, "abcd")) {
+		t.Errorf("expected [{0 1 \"abcd\"}], got %v", matches)
+	}
+}
@@ -0,0 +1,110 @@
+package ahocorasick
+
+import (
+	"slices"
+	"unicode"
+	"unicode/utf8"
+)
+
+// UnicodeCaseInsensitive makes the Trie match patterns regardless of case using Unicode simple
+// case folding, so that "Æbleskiver" also matches "æbleskiver" and "ÆBLESKIVER". Patterns are
+// expected to be UTF-8. Matches still refer to the input as given, including their lengths,
+// which may differ from the pattern length (e.g. the Kelvin sign "K" is three bytes while "k" is
+// one).
+//
+// Every case variant of a rune gets its own path through the trie, but variants of the same
+// encoded length join up again right after the rune. Only runes with variants of different
+// lengths (such as "k" and "K") make the rest of the pattern appear more than once in the trie.
+//
+// It applies to all patterns, including those added before it was called.
+func (tb *TrieBuilder) UnicodeCaseInsensitive() *TrieBuilder {
+	if !tb.foldUnicode {
+		tb.foldASCII = true
+		tb.foldUnicode = true
+		tb.rebuild()
+	}
+	return tb
+}
+
+// foldEnd is a state where a case variant of a pattern ends, and the length of that variant.
+type foldEnd struct {
+	s *state
+	n uint32
+}
+
+// addFolded follows/creates the paths for all case variants of pattern starting at s, which is
+// n bytes deep, and returns the states where the variants end.
+func (tb *TrieBuilder) addFolded(s *state, pattern []byte, n uint32) []foldEnd {
+	if len(pattern) == 0 {
+		return []foldEnd{{s, n}}
+	}
+
+	r, size := utf8.DecodeRune(pattern)
+	if r == utf8.RuneError && size <= 1 {
+		// Invalid bytes are taken as they are.
+		return tb.addFolded(tb.next(s, pattern[0]), pattern[1:], n+1)
+	}
+
+	var ends []foldEnd
+	buf := make([]byte, utf8.UTFMax)
+	for _, variants := range foldVariants(r) {
+		var t *state
+
+		if variants[0] < utf8.RuneSelf {
+			// ASCII letters are folded by the transitions built in Build, so the ASCII
+			// variants of a rune are all handled by one path.
+			t = tb.next(s, toLowerASCII(byte(variants[0])))
+		} else {
+			// The first (smallest) variant has the canonical path, and the last byte of every
+			// other variant leads to the same state.
+			w := utf8.EncodeRune(buf, variants[0])
+			t = tb.path(s, buf[:w])
+			for _, v := range variants[1:] {
+				w := utf8.EncodeRune(buf, v)
+				u := tb.path(s, buf[:w-1])
+				u.trans[buf[w-1]] = t
+			}
+		}
+
+		ends = append(ends, tb.addFolded(t, pattern[size:], n+uint32(utf8.RuneLen(variants[0])))...)
+	}
+
+	return ends
+}
+
+// next follows/creates the transition from s on c.
+func (tb *TrieBuilder) next(s *state, c byte) *state {
+	t, ok := s.trans[c]
+	if !ok {
+		t = tb.addState(c, s)
+		s.trans[c] = t
+	}
+	return t
+}
+
+// path follows/creates the path for the bytes in b starting at s.
+func (tb *TrieBuilder) path(s *state, b []byte) *state {
+	for _, c := range b {
+		s = tb.next(s, c)
+	}
+	return s
+}
+
+// foldVariants returns the runes equivalent to r under simple case folding, grouped by the
+// length of their UTF-8 encoding. Each group is sorted in ascending order.
+func foldVariants(r rune) [][]rune {
+	var groups [utf8.UTFMax + 1][]rune
+	groups[utf8.RuneLen(r)] = append(groups[utf8.RuneLen(r)], r)
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		groups[utf8.RuneLen(f)] = append(groups[utf8.RuneLen(f)], f)
+	}
+
+	variants := make([][]rune, 0, len(groups))
+	for _, group := range groups {
+		if len(group) > 0 {
+			slices.Sort(group)
+			variants = append(variants, group)
+		}
+	}
+	return variants
+}
@@ -0,0 +1,98 @@
+package ahocorasick
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnicodeCaseInsensitive(t *testing.T) {
+	cases := []struct {
+		name     string
+		patterns []string
+		input    string
+		expected []*Match
+	}{
+		{
+			"Norwegian",
+			[]string{"ærlig", "Øl", "på"},
+			"ÆRLIG øL PÅ På",
+			[]*Match{
+				newMatchString(0, 0, "ÆRLIG"),
+				newMatchString(7, 1, "øL"),
+				newMatchString(11, 2, "PÅ"),
+				newMatchString(15, 2, "På"),
+			},
+		},
+		{
+			"Greek",
+			[]string{"Σοφία"},
+			"ΣΟΦΊΑ σοφία",
+			[]*Match{
+				newMatchString(0, 0, "ΣΟΦΊΑ"),
+				newMatchString(11, 0, "σοφία"),
+			},
+		},
+		{
+			"Cyrillic",
+			[]string{"Привет"},
+			"привет ПРИВЕТ",
+			[]*Match{
+				newMatchString(0, 0, "привет"),
+				newMatchString(13, 0, "ПРИВЕТ"),
+			},
+		},
+		{
+			"DifferentLengths",
+			[]string{"ok", "Ås"},
+			"OK oK Åſ",
+			[]*Match{
+				newMatchString(0, 0, "OK"),
+				newMatchString(3, 0, "oK"),
+				newMatchString(8, 1, "Åſ"),
+			},
+		},
+		{
+			"NoMatch",
+			[]string{"æ"},
+			"ae AE",
+			[]*Match{},
+		},
+	}
+
+	for _, c := range cases {
+		// The patterns added before UnicodeCaseInsensitive is called are folded too.
+		for _, tb := range []*TrieBuilder{
+			NewTrieBuilder().UnicodeCaseInsensitive().AddStrings(c.patterns),
+			NewTrieBuilder().AddStrings(c.patterns).CaseInsensitive().UnicodeCaseInsensitive(),
+		} {
+			matches := tb.Build().MatchString(c.input)
+
+			if len(matches) != len(c.expected) {
+				t.Errorf("%s: expected %d matches, got %d: %v", c.name, len(c.expected), len(matches), matches)
+				continue
+			}
+
+			for i := range matches {
+				if !MatchEqual(matches[i], c.expected[i]) {
+					t.Errorf("%s: expected %v, got %v", c.name, c.expected[i], matches[i])
+				}
+			}
+		}
+	}
+}
+
+func TestUnicodeCaseInsensitiveLongPattern(t *testing.T) {
+	// Upper and lower case Cyrillic letters share their lead byte, so every letter has two
+	// transitions to the same state. Each state must still only be visited once when building.
+	pattern := strings.Repeat("п", 40) + "достопримечательность"
+	tr := NewTrieBuilder().
+		UnicodeCaseInsensitive().
+		AddString(pattern).
+		Build()
+
+	input := strings.Repeat("Пп", 20) + "ДОСТОПРИМЕЧАТЕЛЬНОСТЬ"
+	matches := tr.MatchString(input)
+	if len(matches) != 1 || !MatchEqual(matches[0], newMatchString(0, 0, input)) {
+		t.Errorf("expected a match of the whole input, got %v", matches)
+	}
+}
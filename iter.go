@@ -0,0 +1,40 @@
+package ahocorasick
+
+import (
+	"iter"
+)
+
+// All returns an iterator over every match in input, in the same order as Match, without
+// collecting them first. Stopping the iteration stops the search.
+func (tr *Trie) All(input []byte) iter.Seq[Match] {
+	return func(yield func(Match) bool) {
+		tr.walk(input, 0, len(input), func(end int, n, pattern uint32) bool {
+			pos := end - int(n) + 1
+			return yield(Match{pos: int64(pos), pattern: pattern, match: input[pos : end+1]})
+		})
+	}
+}
+
+// AllString is the same as All, but for a string input.
+func (tr *Trie) AllString(input string) iter.Seq[Match] {
+	return tr.All([]byte(input))
+}
+
+// FindAllSeq returns an iterator over the non-overlapping matches in input, in the same order
+// as FindAll, without collecting them first. Stopping the iteration stops the search.
+func (tr *Trie) FindAllSeq(input []byte) iter.Seq[Match] {
+	return func(yield func(Match) bool) {
+		for from := 0; ; {
+			sp, ok := tr.find(input, from)
+			if !ok || !yield(sp.match(input)) {
+				return
+			}
+			from = sp.pos + sp.n
+		}
+	}
+}
+
+// FindAllSeqString is the same as FindAllSeq, but for a string input.
+func (tr *Trie) FindAllSeqString(input string) iter.Seq[Match] {
+	return tr.FindAllSeq([]byte(input))
+}
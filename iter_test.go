@@ -0,0 +1,63 @@
+package ahocorasick
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAll(t *testing.T) {
+	tr := NewTrieBuilder().AddStrings([]string{"a", "ab", "bab", "bc", "bca", "c", "caa"}).Build()
+	input := "abccab"
+
+	expected := tr.MatchString(input)
+	matches := slices.Collect(tr.AllString(input))
+
+	if len(matches) != len(expected) {
+		t.Fatalf("expected %d matches, got %d", len(expected), len(matches))
+	}
+	for i := range matches {
+		if !MatchEqual(&matches[i], expected[i]) {
+			t.Errorf("expected %v, got %v", expected[i], &matches[i])
+		}
+	}
+}
+
+func TestAllBreak(t *testing.T) {
+	tr := NewTrieBuilder().AddString("o").Build()
+
+	n := 0
+	for m := range tr.AllString("Aho-Corasick") {
+		n++
+		if m.Pos() == 2 {
+			break
+		}
+	}
+
+	if n != 1 {
+		t.Errorf("expected to stop after 1 match, got %d", n)
+	}
+}
+
+func TestFindAllSeq(t *testing.T) {
+	tr := NewTrieBuilder().MatchKind(LeftmostLongest).AddStrings([]string{"ab", "abcd", "bc", "d"}).Build()
+	input := "abcdabcd"
+
+	expected := tr.FindAllString(input)
+	matches := slices.Collect(tr.FindAllSeqString(input))
+
+	if len(matches) != len(expected) {
+		t.Fatalf("expected %d matches, got %d", len(expected), len(matches))
+	}
+	for i := range matches {
+		if !MatchEqual(&matches[i], expected[i]) {
+			t.Errorf("expected %v, got %v", expected[i], &matches[i])
+		}
+	}
+
+	for m := range tr.FindAllSeqString(input) {
+		if m.Pos() != 0 {
+			t.Errorf("expected to stop after first match, got %v", &m)
+		}
+		break
+	}
+}
@@ -0,0 +1,147 @@
+package ahocorasick
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrLimitExceeded is matched by the errors returned when a search reaches one of its Limits,
+// i.e. errors.Is(err, ErrLimitExceeded) holds for them.
+var ErrLimitExceeded = errors.New("ahocorasick: match limit exceeded")
+
+// Limits bounds the matches returned by the limited search functions (MatchLimit, FindAllLimit
+// and AppendMatchesLimit), so that adversarial input cannot make them use unbounded memory. A
+// zero field means no limit. Reaching MaxMatches or MaxBytes stops the search, while the matches
+// of a pattern that reached MaxPerPattern are left out and the search goes on for the others.
+type Limits struct {
+	MaxMatches    int // Maximum number of matches in total
+	MaxPerPattern int // Maximum number of matches of any single pattern
+	MaxBytes      int // Maximum total length of the matched bytes
+}
+
+// LimitError is returned when a search finds more matches than allowed by its Limits, along with
+// the matches that were within them. If MaxMatches or MaxBytes stopped the search, it describes
+// the match that did so and Truncated is set. Otherwise, it describes the first match left out
+// because its pattern reached MaxPerPattern, and the other patterns were searched to the end.
+type LimitError struct {
+	Limit     string // Name of the field of Limits that was reached
+	Pattern   uint32 // Pattern of the match that was left out
+	Pos       int64  // Position of the match that was left out
+	Truncated bool   // Whether the search stopped before the end of the input
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("ahocorasick: %s exceeded by pattern %d at position %d", e.Limit, e.Pattern, e.Pos)
+}
+
+// Unwrap returns ErrLimitExceeded.
+func (e *LimitError) Unwrap() error { return ErrLimitExceeded }
+
+// MatchLimit is the same as Match, but only returns the matches within lim, along with a
+// *LimitError if any match was left out.
+func (tr *Trie) MatchLimit(input []byte, lim Limits) ([]*Match, error) {
+	matches, err := tr.AppendMatchesLimit(nil, input, lim)
+	return pointers(matches), err
+}
+
+// MatchLimitString is the same as MatchLimit, but for a string input.
+func (tr *Trie) MatchLimitString(input string, lim Limits) ([]*Match, error) {
+	return tr.MatchLimit([]byte(input), lim)
+}
+
+// AppendMatchesLimit is the same as AppendMatches, but only appends the matches within lim,
+// returning the extended slice and a *LimitError if any match was left out. Only the appended
+// matches count towards lim.
+func (tr *Trie) AppendMatchesLimit(dst []Match, input []byte, lim Limits) ([]Match, error) {
+	l := tr.newLimiter(lim)
+	tr.walk(input, 0, len(input), func(end int, n, pattern uint32) bool {
+		pos := end - int(n) + 1
+		if l.admit(int64(pos), n, pattern) {
+			dst = append(dst, Match{pos: int64(pos), pattern: pattern, match: input[pos : end+1]})
+		}
+		return !l.done
+	})
+	return dst, l.error()
+}
+
+// FindAllLimit is the same as FindAll, but only returns the matches within lim, along with a
+// *LimitError if any match was left out. A match left out because its pattern reached
+// MaxPerPattern still takes up its bytes, so the other matches are the same as with FindAll.
+func (tr *Trie) FindAllLimit(input []byte, lim Limits) ([]*Match, error) {
+	l := tr.newLimiter(lim)
+	var found []Match
+	for from := 0; ; {
+		sp, ok := tr.find(input, from)
+		if !ok {
+			break
+		}
+		if l.admit(int64(sp.pos), uint32(sp.n), sp.pattern) {
+			found = append(found, sp.match(input))
+		}
+		if l.done {
+			break
+		}
+		from = sp.pos + sp.n
+	}
+	return pointers(found), l.error()
+}
+
+// FindAllLimitString is the same as FindAllLimit, but for a string input.
+func (tr *Trie) FindAllLimitString(input string, lim Limits) ([]*Match, error) {
+	return tr.FindAllLimit([]byte(input), lim)
+}
+
+// limiter keeps track of the matches admitted under some Limits.
+type limiter struct {
+	lim        Limits
+	matches    int
+	bytes      int
+	perPattern []int // Number of matches of each pattern, only if lim.MaxPerPattern is set
+	done       bool  // Whether MaxMatches or MaxBytes was reached
+	err        *LimitError
+}
+
+func (tr *Trie) newLimiter(lim Limits) *limiter {
+	l := &limiter{lim: lim}
+	if lim.MaxPerPattern > 0 {
+		l.perPattern = make([]int, tr.numPatterns)
+	}
+	return l
+}
+
+// admit counts a match of length n for pattern at pos, and reports whether it is within the
+// limits. If a match exceeds MaxMatches or MaxBytes, the search is done, and the match is kept for
+// error. Otherwise, the first match refused is kept.
+func (l *limiter) admit(pos int64, n, pattern uint32) bool {
+	var limit string
+	switch {
+	case l.lim.MaxMatches > 0 && l.matches >= l.lim.MaxMatches:
+		limit = "MaxMatches"
+		l.done = true
+	case l.perPattern != nil && l.perPattern[pattern] >= l.lim.MaxPerPattern:
+		limit = "MaxPerPattern"
+	case l.lim.MaxBytes > 0 && l.bytes+int(n) > l.lim.MaxBytes:
+		limit = "MaxBytes"
+		l.done = true
+	default:
+		l.matches++
+		l.bytes += int(n)
+		if l.perPattern != nil {
+			l.perPattern[pattern]++
+		}
+		return true
+	}
+
+	if l.err == nil || l.done {
+		l.err = &LimitError{Limit: limit, Pattern: pattern, Pos: pos, Truncated: l.done}
+	}
+	return false
+}
+
+// error returns the error for the match refused by admit, if any.
+func (l *limiter) error() error {
+	if l.err == nil {
+		return nil
+	}
+	return l.err
+}
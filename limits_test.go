@@ -0,0 +1,156 @@
+package ahocorasick
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMatchLimit(t *testing.T) {
+	tr := NewTrieBuilder().
+		AddStrings([]string{"a", "aa", "aaa", "b"}).
+		Build()
+
+	cases := []struct {
+		input    string
+		lim      Limits
+		expected []*Match
+		limit    string
+	}{
+		{"aab", Limits{}, []*Match{
+			newMatchString(0, 0, "a"),
+			newMatchString(0, 1, "aa"),
+			newMatchString(1, 0, "a"),
+			newMatchString(2, 3, "b"),
+		}, ""},
+		{"aab", Limits{MaxMatches: 4}, []*Match{
+			newMatchString(0, 0, "a"),
+			newMatchString(0, 1, "aa"),
+			newMatchString(1, 0, "a"),
+			newMatchString(2, 3, "b"),
+		}, ""},
+		{"aab", Limits{MaxMatches: 2}, []*Match{
+			newMatchString(0, 0, "a"),
+			newMatchString(0, 1, "aa"),
+		}, "MaxMatches"},
+		{"aab", Limits{MaxPerPattern: 1}, []*Match{
+			newMatchString(0, 0, "a"),
+			newMatchString(0, 1, "aa"),
+			newMatchString(2, 3, "b"),
+		}, "MaxPerPattern"},
+		{"aabb", Limits{MaxPerPattern: 1, MaxMatches: 3}, []*Match{
+			newMatchString(0, 0, "a"),
+			newMatchString(0, 1, "aa"),
+			newMatchString(2, 3, "b"),
+		}, "MaxMatches"},
+		{"aab", Limits{MaxBytes: 3}, []*Match{
+			newMatchString(0, 0, "a"),
+			newMatchString(0, 1, "aa"),
+		}, "MaxBytes"},
+	}
+
+	for _, c := range cases {
+		matches, err := tr.MatchLimitString(c.input, c.lim)
+
+		if len(matches) != len(c.expected) {
+			t.Errorf("%+v: expected %d matches, got %d: %v", c.lim, len(c.expected), len(matches), matches)
+			continue
+		}
+		for i := range matches {
+			if !MatchEqual(matches[i], c.expected[i]) {
+				t.Errorf("%+v: expected %v, got %v", c.lim, c.expected[i], matches[i])
+			}
+		}
+
+		var le *LimitError
+		if c.limit == "" {
+			if err != nil {
+				t.Errorf("%+v: unexpected error: %v", c.lim, err)
+			}
+		} else if !errors.As(err, &le) || le.Limit != c.limit || !errors.Is(err, ErrLimitExceeded) {
+			t.Errorf("%+v: expected %s to be exceeded, got %v", c.lim, c.limit, err)
+		} else if truncated := c.limit != "MaxPerPattern"; le.Truncated != truncated {
+			t.Errorf("%+v: expected truncated to be %t, got %t", c.lim, truncated, le.Truncated)
+		}
+	}
+}
+
+func TestMatchLimitQuadratic(t *testing.T) {
+	patterns := make([]string, 100)
+	for i := range patterns {
+		patterns[i] = strings.Repeat("a", i+1)
+	}
+	tr := NewTrieBuilder().AddStrings(patterns).Build()
+	input := strings.Repeat("a", 10000)
+
+	matches, err := tr.MatchLimitString(input, Limits{MaxMatches: 1000})
+	if len(matches) != 1000 || !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("expected 1000 matches and ErrLimitExceeded, got %d and %v", len(matches), err)
+	}
+
+	var le *LimitError
+	if !errors.As(err, &le) || le.Pos != 10 || le.Pattern != 34 {
+		t.Errorf("expected the limit to be exceeded by pattern 34 at position 10, got %v", err)
+	}
+}
+
+func TestFindAllLimit(t *testing.T) {
+	tr := NewTrieBuilder().
+		MatchKind(LeftmostLongest).
+		AddStrings([]string{"a", "aa", "b"}).
+		Build()
+
+	// The second "aa" is left out, but "b" is still found after it.
+	matches, err := tr.FindAllLimitString("aaaab", Limits{MaxPerPattern: 1})
+	expected := []*Match{newMatchString(0, 1, "aa"), newMatchString(4, 2, "b")}
+	if !equalMatches(matches, expected) {
+		t.Errorf("expected %v, got %v", expected, matches)
+	}
+
+	var le *LimitError
+	if !errors.As(err, &le) || le.Limit != "MaxPerPattern" || le.Pos != 2 || le.Pattern != 1 {
+		t.Errorf("expected MaxPerPattern to be exceeded by pattern 1 at position 2, got %v", err)
+	}
+
+	matches, err = tr.FindAllLimitString("aaaab", Limits{MaxMatches: 3})
+	if len(matches) != 3 || err != nil {
+		t.Errorf("expected 3 matches and no error, got %d and %v", len(matches), err)
+	}
+}
+
+func TestMatchLimitPerPattern(t *testing.T) {
+	tr := NewTrieBuilder().
+		AddStrings([]string{"a", "needle"}).
+		Build()
+
+	// A frequent pattern reaching its limit does not hide the matches of the others.
+	input := strings.Repeat("a", 1000) + "needle"
+	matches, err := tr.MatchLimitString(input, Limits{MaxPerPattern: 10})
+
+	if len(matches) != 11 || !MatchEqual(matches[10], newMatchString(1000, 1, "needle")) {
+		t.Errorf("expected 10 matches of \"a\" and one of \"needle\", got %d: %v", len(matches), matches[10:])
+	}
+
+	var le *LimitError
+	if !errors.As(err, &le) || le.Limit != "MaxPerPattern" || le.Pos != 10 || le.Pattern != 0 {
+		t.Errorf("expected MaxPerPattern to be exceeded by pattern 0 at position 10, got %v", err)
+	}
+}
+
+func TestMatchLimitTruncated(t *testing.T) {
+	tr := NewTrieBuilder().
+		AddStrings([]string{"a", "b"}).
+		Build()
+
+	// MaxMatches stops the search after "a" has reached MaxPerPattern, which must be reported.
+	matches, err := tr.MatchLimitString("aaabbbbbbb", Limits{MaxPerPattern: 1, MaxMatches: 2})
+	expected := []*Match{newMatchString(0, 0, "a"), newMatchString(3, 1, "b")}
+	if !equalMatches(matches, expected) {
+		t.Errorf("expected %v, got %v", expected, matches)
+	}
+
+	var le *LimitError
+	if !errors.As(err, &le) || le.Limit != "MaxMatches" || !le.Truncated || le.Pos != 4 || le.Pattern != 1 {
+		t.Errorf("expected MaxMatches to truncate the search at position 4, got %+v", err)
+	}
+}
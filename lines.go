@@ -0,0 +1,97 @@
+package ahocorasick
+
+import (
+	"bytes"
+	"fmt"
+	"iter"
+)
+
+// LineMatch is a Match that also knows the line it starts on.
+type LineMatch struct {
+	Match
+	line      int
+	lineStart int
+	lineEnd   int
+}
+
+func (m *LineMatch) String() string {
+	return fmt.Sprintf("{%d %d:%d %d %q}", m.pos, m.line, m.Column(), m.pattern, m.match)
+}
+
+// Line returns the 1-based number of the line the match starts on.
+func (m *LineMatch) Line() int { return m.line }
+
+// Column returns the 1-based byte column of the match within its line.
+func (m *LineMatch) Column() int { return int(m.pos) - m.lineStart + 1 }
+
+// LineStart returns the byte position where the line of the match starts.
+func (m *LineMatch) LineStart() int { return m.lineStart }
+
+// LineEnd returns the byte position where the line of the match ends, not counting the newline.
+func (m *LineMatch) LineEnd() int { return m.lineEnd }
+
+// AllLines is the same as All, but the matches also carry their line and column. Newlines are
+// counted along with the search, so the input is only traversed once.
+func (tr *Trie) AllLines(input []byte) iter.Seq[LineMatch] {
+	return withLines(input, tr.All(input))
+}
+
+// AllLinesString is the same as AllLines, but for a string input.
+func (tr *Trie) AllLinesString(input string) iter.Seq[LineMatch] {
+	return tr.AllLines([]byte(input))
+}
+
+// FindAllLines is the same as FindAllSeq, but the matches also carry their line and column.
+func (tr *Trie) FindAllLines(input []byte) iter.Seq[LineMatch] {
+	return withLines(input, tr.FindAllSeq(input))
+}
+
+// FindAllLinesString is the same as FindAllLines, but for a string input.
+func (tr *Trie) FindAllLinesString(input string) iter.Seq[LineMatch] {
+	return tr.FindAllLines([]byte(input))
+}
+
+// withLines adds lines to matches, which must be ordered by their end position. Only the input
+// up to the end of the latest match is counted, and the end of a line is only looked for once.
+func withLines(input []byte, matches iter.Seq[Match]) iter.Seq[LineMatch] {
+	return func(yield func(LineMatch) bool) {
+		counted := 0    // Bytes counted so far
+		newlines := 0   // Newlines in input[:counted]
+		lastLine := 0   // Start of the last line in input[:counted]
+		lineStart := -1 // Start of the line whose end is known
+		lineEnd := -1   // End of that line
+
+		for m := range matches {
+			start := int(m.pos)
+			end := start + len(m.match)
+
+			for i, c := range input[counted:end] {
+				if c == '\n' {
+					newlines++
+					lastLine = counted + i + 1
+				}
+			}
+			counted = end
+
+			lm := LineMatch{Match: m, line: newlines + 1, lineStart: lastLine}
+			if inside := bytes.Count(m.match, []byte{'\n'}); inside > 0 {
+				// The match spans several lines, so its own line started before the last one.
+				lm.line -= inside
+				lm.lineStart = bytes.LastIndexByte(input[:start], '\n') + 1
+			}
+
+			if lm.lineStart != lineStart {
+				lineStart = lm.lineStart
+				lineEnd = len(input)
+				if i := bytes.IndexByte(input[start:], '\n'); i >= 0 {
+					lineEnd = start + i
+				}
+			}
+			lm.lineEnd = lineEnd
+
+			if !yield(lm) {
+				return
+			}
+		}
+	}
+}
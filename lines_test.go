@@ -0,0 +1,55 @@
+package ahocorasick
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestAllLines(t *testing.T) {
+	ibsen, err := os.ReadFile("./test_data/Ibsen.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tr := NewTrieBuilder().AddStrings([]string{"Hedvig", "dvig", "\nHJALMAR", "Gina"}).Build()
+
+	n := 0
+	for m := range tr.AllLines(ibsen) {
+		n++
+		start := int(m.Pos())
+		line := bytes.Count(ibsen[:start], []byte{'\n'}) + 1
+		lineStart := bytes.LastIndexByte(ibsen[:start], '\n') + 1
+		lineEnd := lineStart + bytes.IndexByte(ibsen[lineStart:], '\n')
+
+		if m.Line() != line || m.Column() != start-lineStart+1 || m.LineStart() != lineStart || m.LineEnd() != lineEnd {
+			t.Fatalf("%v: expected %d:%d in line %d-%d, got %d:%d in line %d-%d",
+				&m, line, start-lineStart+1, lineStart, lineEnd, m.Line(), m.Column(), m.LineStart(), m.LineEnd())
+		}
+	}
+
+	if expected := tr.Count(ibsen); n != expected {
+		t.Errorf("expected %d matches, got %d", expected, n)
+	}
+}
+
+func TestFindAllLines(t *testing.T) {
+	tr := NewTrieBuilder().MatchKind(LeftmostLongest).AddStrings([]string{"TODO", "FIXME", "TODO:"}).Build()
+	input := "package main\n\n// TODO: tests\nfunc main() {} // FIXME\n// TODO"
+
+	expected := [][4]int{{3, 4, 14, 28}, {4, 19, 29, 52}, {5, 4, 53, 60}}
+	i := 0
+	for m := range tr.FindAllLinesString(input) {
+		if i >= len(expected) {
+			t.Fatalf("unexpected match %v", &m)
+		}
+		got := [4]int{m.Line(), m.Column(), m.LineStart(), m.LineEnd()}
+		if got != expected[i] {
+			t.Errorf("%v: expected %v, got %v", &m, expected[i], got)
+		}
+		i++
+	}
+	if i != len(expected) {
+		t.Errorf("expected %d matches, got %d", len(expected), i)
+	}
+}
@@ -7,25 +7,39 @@ import (
 
 // Match represents a matched pattern in the input.
 type Match struct {
-	pos     uint32
+	pos     int64
 	pattern uint32
 	match   []byte
 }
 
 func newMatch(pos, pattern uint32, match []byte) *Match {
-	return &Match{pos, pattern, match}
+	return &Match{int64(pos), pattern, match}
 }
 
 func newMatchString(pos, pattern uint32, match string) *Match {
-	return &Match{pos: pos, pattern: pattern, match: []byte(match)}
+	return &Match{pos: int64(pos), pattern: pattern, match: []byte(match)}
+}
+
+// pointers returns pointers to the matches, sharing their backing array instead of allocating
+// every match separately.
+func pointers(matches []Match) []*Match {
+	ptrs := make([]*Match, len(matches))
+	for i := range matches {
+		ptrs[i] = &matches[i]
+	}
+	return ptrs
 }
 
 func (m *Match) String() string {
 	return fmt.Sprintf("{%d %d %q}", m.pos, m.pattern, m.match)
 }
 
-// Pos returns the byte position of the match.
-func (m *Match) Pos() uint32 { return m.pos }
+// Pos returns the byte position of the match. It wraps around for positions beyond 4 GiB; use
+// Pos64 for those.
+func (m *Match) Pos() uint32 { return uint32(m.pos) }
+
+// Pos64 returns the byte position of the match as a 64-bit integer.
+func (m *Match) Pos64() int64 { return m.pos }
 
 // Pattern returns the pattern id of the match.
 func (m *Match) Pattern() uint32 { return m.pattern }
@@ -0,0 +1,158 @@
+package ahocorasick
+
+import (
+	"fmt"
+	"iter"
+	"math/bits"
+	"strings"
+)
+
+// PatternSet is a set of pattern numbers, stored as a bitset.
+type PatternSet struct {
+	bits []uint64
+	n    int
+}
+
+// NewPatternSet creates an empty PatternSet that can hold the patterns 0 to n - 1.
+func NewPatternSet(n int) *PatternSet {
+	return &PatternSet{
+		bits: make([]uint64, (n+63)/64),
+		n:    n,
+	}
+}
+
+// Add adds pattern to the set. It panics if pattern is not below Cap().
+func (ps *PatternSet) Add(pattern uint32) {
+	if int(pattern) >= ps.n {
+		panic(fmt.Sprintf("ahocorasick: pattern %d out of range for PatternSet of %d", pattern, ps.n))
+	}
+	ps.bits[pattern/64] |= 1 << (pattern % 64)
+}
+
+// Remove removes pattern from the set.
+func (ps *PatternSet) Remove(pattern uint32) {
+	if int(pattern) < ps.n {
+		ps.bits[pattern/64] &^= 1 << (pattern % 64)
+	}
+}
+
+// Has reports whether pattern is in the set.
+func (ps *PatternSet) Has(pattern uint32) bool {
+	return int(pattern) < ps.n && ps.bits[pattern/64]&(1<<(pattern%64)) != 0
+}
+
+// Len returns the number of patterns in the set.
+func (ps *PatternSet) Len() int {
+	n := 0
+	for _, w := range ps.bits {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// Cap returns the number of patterns the set can hold.
+func (ps *PatternSet) Cap() int { return ps.n }
+
+// Full reports whether every pattern the set can hold is in it.
+func (ps *PatternSet) Full() bool { return ps.Len() == ps.n }
+
+// Iter returns an iterator over the patterns in the set, in ascending order.
+func (ps *PatternSet) Iter() iter.Seq[uint32] {
+	return func(yield func(uint32) bool) {
+		for i, w := range ps.bits {
+			for w != 0 {
+				b := bits.TrailingZeros64(w)
+				if !yield(uint32(i*64 + b)) {
+					return
+				}
+				w &^= 1 << b
+			}
+		}
+	}
+}
+
+// Clone returns a copy of the set.
+func (ps *PatternSet) Clone() *PatternSet {
+	return &PatternSet{
+		bits: append([]uint64(nil), ps.bits...),
+		n:    ps.n,
+	}
+}
+
+// Union returns a new set with the patterns in either ps or other.
+func (ps *PatternSet) Union(other *PatternSet) *PatternSet {
+	return ps.combine(other, func(a, b uint64) uint64 { return a | b })
+}
+
+// Intersect returns a new set with the patterns in both ps and other.
+func (ps *PatternSet) Intersect(other *PatternSet) *PatternSet {
+	return ps.combine(other, func(a, b uint64) uint64 { return a & b })
+}
+
+// Difference returns a new set with the patterns in ps that are not in other.
+func (ps *PatternSet) Difference(other *PatternSet) *PatternSet {
+	return ps.combine(other, func(a, b uint64) uint64 { return a &^ b })
+}
+
+// Equal reports whether ps and other contain the same patterns.
+func (ps *PatternSet) Equal(other *PatternSet) bool {
+	for i := range max(len(ps.bits), len(other.bits)) {
+		if ps.word(i) != other.word(i) {
+			return false
+		}
+	}
+	return true
+}
+
+func (ps *PatternSet) String() string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for pattern := range ps.Iter() {
+		if b.Len() > 1 {
+			b.WriteByte(' ')
+		}
+		fmt.Fprint(&b, pattern)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// combine returns a new set, large enough for both ps and other, where every word is op applied
+// to the corresponding words of ps and other.
+func (ps *PatternSet) combine(other *PatternSet, op func(a, b uint64) uint64) *PatternSet {
+	res := NewPatternSet(max(ps.n, other.n))
+	for i := range res.bits {
+		res.bits[i] = op(ps.word(i), other.word(i))
+	}
+	return res
+}
+
+// word returns the i'th word of the bitset, or 0 if the set is too small to have it.
+func (ps *PatternSet) word(i int) uint64 {
+	if i < len(ps.bits) {
+		return ps.bits[i]
+	}
+	return 0
+}
+
+// MatchSet returns the set of patterns that occur at least once in input, without collecting
+// the matches. The search stops as soon as every pattern has been seen.
+func (tr *Trie) MatchSet(input []byte) *PatternSet {
+	set := NewPatternSet(tr.NumPatterns())
+	remaining := tr.numPatterns
+
+	tr.Walk(input, func(end, n, pattern uint32) bool {
+		if !set.Has(pattern) {
+			set.Add(pattern)
+			remaining--
+		}
+		return remaining > 0
+	})
+
+	return set
+}
+
+// MatchSetString is the same as MatchSet, but for a string input.
+func (tr *Trie) MatchSetString(input string) *PatternSet {
+	return tr.MatchSet([]byte(input))
+}
@@ -0,0 +1,69 @@
+package ahocorasick
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPatternSet(t *testing.T) {
+	a := NewPatternSet(130)
+	for _, p := range []uint32{0, 3, 64, 129} {
+		a.Add(p)
+	}
+	b := NewPatternSet(70)
+	for _, p := range []uint32{3, 64, 65} {
+		b.Add(p)
+	}
+
+	if !a.Has(129) || a.Has(1) || a.Has(1000) {
+		t.Errorf("unexpected membership in %v", a)
+	}
+	if a.Len() != 4 || a.Cap() != 130 || a.Full() {
+		t.Errorf("expected 4 of 130 patterns, got %d of %d", a.Len(), a.Cap())
+	}
+	if got := slices.Collect(a.Iter()); !slices.Equal(got, []uint32{0, 3, 64, 129}) {
+		t.Errorf("expected [0 3 64 129], got %v", got)
+	}
+
+	cases := []struct {
+		name     string
+		set      *PatternSet
+		expected string
+	}{
+		{"Union", a.Union(b), "{0 3 64 65 129}"},
+		{"Intersect", a.Intersect(b), "{3 64}"},
+		{"Difference", a.Difference(b), "{0 129}"},
+		{"ReverseDifference", b.Difference(a), "{65}"},
+	}
+	for _, c := range cases {
+		if c.set.String() != c.expected {
+			t.Errorf("%s: expected %s, got %v", c.name, c.expected, c.set)
+		}
+	}
+
+	c := a.Clone()
+	c.Remove(129)
+	if !a.Has(129) || c.Has(129) || c.Equal(a) {
+		t.Errorf("unexpected clone %v of %v", c, a)
+	}
+	if !a.Intersect(b).Equal(b.Intersect(a)) {
+		t.Errorf("expected intersection to be symmetric")
+	}
+}
+
+func TestMatchSet(t *testing.T) {
+	tr := NewTrieBuilder().AddStrings([]string{"or", "amet", "elit", "Knuth"}).Build()
+
+	set := tr.MatchSetString("Lorem ipsum dolor sit amet, consectetur adipiscing elit.")
+	if set.String() != "{0 1 2}" {
+		t.Errorf("expected {0 1 2}, got %v", set)
+	}
+	if set.Cap() != tr.NumPatterns() {
+		t.Errorf("expected capacity %d, got %d", tr.NumPatterns(), set.Cap())
+	}
+
+	set = tr.MatchSetString("Knuth, dolor sit amet elit")
+	if !set.Full() {
+		t.Errorf("expected all patterns, got %v", set)
+	}
+}
@@ -0,0 +1,52 @@
+package ahocorasick
+
+import (
+	"iter"
+)
+
+// LongestPrefix returns the length and pattern of the longest pattern that is a prefix of input.
+// Only the trie itself is followed from the start of the input, so the search stops as soon as
+// no pattern can be a longer prefix. The result ok is false if no pattern is a prefix of input.
+func (tr *Trie) LongestPrefix(input []byte) (n int, pattern uint32, ok bool) {
+	for m := range tr.Prefixes(input) {
+		n, pattern, ok = len(m.match), m.pattern, true
+	}
+	return n, pattern, ok
+}
+
+// LongestPrefixString is the same as LongestPrefix, but for a string input.
+func (tr *Trie) LongestPrefixString(input string) (n int, pattern uint32, ok bool) {
+	return tr.LongestPrefix([]byte(input))
+}
+
+// Prefixes returns an iterator over the patterns that are prefixes of input, from the shortest
+// to the longest. All the matches are at position 0.
+func (tr *Trie) Prefixes(input []byte) iter.Seq[Match] {
+	return func(yield func(Match) bool) {
+		failTrans := tr.failTrans
+		depth := tr.depth
+		check := tr.constrained()
+
+		s := rootState
+		for i, c := range input {
+			// A transition to a state one level deeper follows an edge of the trie; any other
+			// transition means the input has left the trie.
+			t := failTrans[s][c]
+			if depth[t] != depth[s]+1 {
+				return
+			}
+			s = t
+
+			if n := tr.dict[s]; n != 0 && (!check || tr.accept(input, i, n, tr.pattern[s])) {
+				if !yield(Match{pos: 0, pattern: tr.pattern[s], match: input[:n]}) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// PrefixesString is the same as Prefixes, but for a string input.
+func (tr *Trie) PrefixesString(input string) iter.Seq[Match] {
+	return tr.Prefixes([]byte(input))
+}
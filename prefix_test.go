@@ -0,0 +1,92 @@
+package ahocorasick
+
+import (
+	"testing"
+)
+
+func TestLongestPrefix(t *testing.T) {
+	tr := NewTrieBuilder().AddStrings([]string{"/api", "/api/users", "/", "/apiary", "users"}).Build()
+
+	cases := []struct {
+		input   string
+		n       int
+		pattern uint32
+		ok      bool
+	}{
+		{"/api/users/42", 10, 1, true},
+		{"/api/user", 4, 0, true},
+		{"/apiar", 4, 0, true},
+		{"/index.html", 1, 2, true},
+		{"users", 5, 4, true},
+		{"x/api/users", 0, 0, false},
+		{"", 0, 0, false},
+	}
+
+	for _, c := range cases {
+		n, pattern, ok := tr.LongestPrefixString(c.input)
+		if n != c.n || pattern != c.pattern || ok != c.ok {
+			t.Errorf("%q: expected %d, %d, %t, got %d, %d, %t", c.input, c.n, c.pattern, c.ok, n, pattern, ok)
+		}
+	}
+}
+
+func TestPrefixes(t *testing.T) {
+	cases := []struct {
+		name     string
+		builder  *TrieBuilder
+		input    string
+		expected []*Match
+	}{
+		{
+			"Prefix",
+			NewTrieBuilder().AddStrings([]string{"Aho-Corasick", "Aho-Cora", "Aho", "A", "Corasick"}),
+			"Aho-Corasick",
+			[]*Match{
+				newMatchString(0, 3, "A"),
+				newMatchString(0, 2, "Aho"),
+				newMatchString(0, 1, "Aho-Cora"),
+				newMatchString(0, 0, "Aho-Corasick"),
+			},
+		},
+		{
+			"Anchored",
+			NewTrieBuilder().AddStrings([]string{"ho", "o-C"}),
+			"Aho-Corasick",
+			[]*Match{},
+		},
+		{
+			"CaseInsensitive",
+			NewTrieBuilder().CaseInsensitive().AddStrings([]string{"get", "get /"}),
+			"GET /index.html",
+			[]*Match{
+				newMatchString(0, 0, "GET"),
+				newMatchString(0, 1, "GET /"),
+			},
+		},
+		{
+			"WordBoundary",
+			NewTrieBuilder().WordBoundary(ASCIIWordBoundary).AddStrings([]string{"if", "iff"}),
+			"iffy",
+			[]*Match{},
+		},
+	}
+
+	for _, c := range cases {
+		tr := c.builder.Build()
+		var matches []Match
+		for m := range tr.PrefixesString(c.input) {
+			matches = append(matches, m)
+		}
+
+		if len(matches) != len(c.expected) {
+			t.Errorf("%s: expected %d matches, got %d", c.name, len(c.expected), len(matches))
+			continue
+		}
+
+		for i := range matches {
+			if !MatchEqual(&matches[i], c.expected[i]) {
+				t.Errorf("%s: expected %v, got %v", c.name, c.expected[i], &matches[i])
+			}
+		}
+	}
+}
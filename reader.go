@@ -0,0 +1,159 @@
+package ahocorasick
+
+import (
+	"io"
+	"slices"
+	"unicode/utf8"
+)
+
+// readerChunk is the number of bytes MatchReader reads at a time.
+const readerChunk = 64 << 10
+
+// MatchReader runs the algorithm on the bytes read from r, calling fn on every match until r
+// returns io.EOF, and stops early if fn returns false. The automaton carries on from one read to
+// the next, so matches spanning several reads are found too. Positions are offsets in the whole
+// stream, so use Pos64 for streams larger than 4 GiB.
+//
+// The matched bytes point into a buffer that only keeps the end of the stream, as much as the
+// longest pattern needs, and are only valid until fn returns. Any error from r other than io.EOF
+// stops the search and is returned.
+func (tr *Trie) MatchReader(r io.Reader, fn func(m Match) bool) error {
+	// Bytes kept before the unsearched part of the buffer: the longest match but its last byte,
+	// and a rune before it for the boundary checks.
+	back := max(0, tr.maxLen-1)
+	delay := 0
+	if tr.constrained() {
+		back += utf8.UTFMax
+		delay = utf8.UTFMax // Bytes after a match needed for the boundary checks
+	}
+
+	buf := make([]byte, 0, back+delay+readerChunk)
+	var base int64 // Stream offset of buf[0]
+	from := 0      // Start of the unsearched part of buf
+	stopped := false
+	walk := func(end int, n, pattern uint32) bool {
+		pos := end - int(n) + 1
+		if !fn(Match{pos: base + int64(pos), pattern: pattern, match: buf[pos : end+1]}) {
+			stopped = true
+			return false
+		}
+		return true
+	}
+
+	s := rootState
+	for {
+		n, err := r.Read(buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+		eof := err == io.EOF
+
+		to := len(buf)
+		if !eof {
+			to = max(from, to-delay)
+		}
+		s, _ = tr.scan(s, buf, from, to, walk)
+		if stopped || eof {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// Drop the bytes no longer needed to make room for the next read.
+		if keep := max(0, to-back); keep > 0 && len(buf) == cap(buf) {
+			buf = buf[:copy(buf, buf[keep:])]
+			base += int64(keep)
+			to -= keep
+		}
+		from = to
+	}
+}
+
+// NewReplacingReader returns a reader of the bytes read from r, where the non-overlapping
+// matches of trie, as found by FindAll, are replaced by the entry in replacements for their
+// pattern, like ReplaceAll does. A nil entry, or a missing entry when replacements is shorter
+// than the number of patterns, leaves the matches of that pattern unchanged.
+//
+// Besides one read from r, only the bytes that may still be part of a match are buffered. Any
+// error from r other than io.EOF is returned once the bytes before it have been read, and the
+// bytes that could still be part of a match are dropped.
+func NewReplacingReader(r io.Reader, trie *Trie, replacements [][]byte) io.Reader {
+	rr := &replacingReader{r: r, tr: trie, replacements: replacements}
+	if trie.constrained() {
+		rr.back = utf8.UTFMax
+		rr.delay = utf8.UTFMax
+	}
+	rr.buf = make([]byte, 0, rr.back+trie.maxLen+rr.delay+readerChunk)
+	return rr
+}
+
+// replacingReader is the reader returned by NewReplacingReader.
+type replacingReader struct {
+	r            io.Reader
+	tr           *Trie
+	replacements [][]byte
+
+	back  int // Bytes kept before the search position for the boundary checks
+	delay int // Bytes after a match needed for the boundary checks
+
+	buf  []byte // Bytes read from r that are not written out yet, after the back bytes before them
+	from int    // Position in buf where the next search starts
+
+	out    []byte // Bytes written out
+	outPos int    // Position in out of the next byte to return
+	err    error  // Error from r, returned once out has been read
+}
+
+func (rr *replacingReader) Read(p []byte) (int, error) {
+	for rr.outPos == len(rr.out) {
+		if rr.err != nil {
+			return 0, rr.err
+		}
+		rr.fill()
+	}
+
+	n := copy(p, rr.out[rr.outPos:])
+	rr.outPos += n
+	return n, nil
+}
+
+// fill reads from r and writes out the bytes before the position where a match can still start.
+func (rr *replacingReader) fill() {
+	rr.out = rr.out[:0]
+	rr.outPos = 0
+
+	// Drop the bytes no longer needed to make room for the next read.
+	if drop := rr.from - rr.back; drop > 0 {
+		rr.buf = rr.buf[:copy(rr.buf, rr.buf[drop:])]
+		rr.from -= drop
+	}
+	rr.buf = slices.Grow(rr.buf, readerChunk)
+
+	n, err := rr.r.Read(rr.buf[len(rr.buf) : len(rr.buf)+readerChunk])
+	rr.buf = rr.buf[:len(rr.buf)+n]
+	rr.err = err
+	eof := err == io.EOF
+
+	to := len(rr.buf)
+	if !eof {
+		to = max(rr.from, to-rr.delay)
+	}
+	for {
+		sp, ok, resume := rr.tr.search(rr.buf, rr.from, to, eof)
+		if !ok {
+			if eof {
+				resume = len(rr.buf)
+			}
+			rr.out = append(rr.out, rr.buf[rr.from:resume]...)
+			rr.from = resume
+			return
+		}
+
+		rr.out = append(rr.out, rr.buf[rr.from:sp.pos]...)
+		if with := replacementFor(rr.replacements, sp.pattern); with != nil {
+			rr.out = append(rr.out, with...)
+		} else {
+			rr.out = append(rr.out, rr.buf[sp.pos:sp.pos+sp.n]...)
+		}
+		rr.from = sp.pos + sp.n
+	}
+}
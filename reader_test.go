@@ -0,0 +1,165 @@
+package ahocorasick
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func readerMatches(t *testing.T, tr *Trie, r io.Reader) []*Match {
+	t.Helper()
+
+	var matches []Match
+	err := tr.MatchReader(r, func(m Match) bool {
+		m.match = bytes.Clone(m.match)
+		matches = append(matches, m)
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pointers(matches)
+}
+
+func TestMatchReader(t *testing.T) {
+	input := contextInput()
+
+	trs := map[string]*Trie{
+		"plain": NewTrieBuilder().
+			AddStrings([]string{"ab", "abcdabcdabcd", "bcd", "d a", "dabc"}).
+			Build(),
+		"boundary": NewTrieBuilder().
+			WordBoundary(UnicodeWordBoundary).
+			AddStrings([]string{"ab", "abcd", "bcd", "d a", "dabc"}).
+			Build(),
+	}
+
+	for name, tr := range trs {
+		expected := tr.Match(input)
+
+		matches := readerMatches(t, tr, bytes.NewReader(input))
+		if !equalMatches(matches, expected) {
+			t.Errorf("%s: expected %d matches, got %d", name, len(expected), len(matches))
+		}
+
+		matches = readerMatches(t, tr, iotest.OneByteReader(bytes.NewReader(input[:1000])))
+		if expected := tr.Match(input[:1000]); !equalMatches(matches, expected) {
+			t.Errorf("%s: expected %d matches reading a byte at a time, got %d", name, len(expected), len(matches))
+		}
+	}
+}
+
+func TestMatchReaderBoundary(t *testing.T) {
+	tr := NewTrieBuilder().
+		WordBoundary(UnicodeWordBoundary).
+		AddStrings([]string{"ærlig", "lig"}).
+		Build()
+
+	// The boundary after "lig" is only known once the next rune has been read.
+	for _, input := range []string{"ærlig", "ærligé", "u ærlig ut", "ærlig\xc3"} {
+		matches := readerMatches(t, tr, iotest.OneByteReader(strings.NewReader(input)))
+		if expected := tr.MatchString(input); !equalMatches(matches, expected) {
+			t.Errorf("%q: expected %v, got %v", input, expected, matches)
+		}
+	}
+}
+
+func TestMatchReaderStop(t *testing.T) {
+	tr := NewTrieBuilder().AddString("o").Build()
+
+	n := 0
+	err := tr.MatchReader(strings.NewReader("Aho-Corasick"), func(m Match) bool {
+		n++
+		return false
+	})
+
+	if err != nil || n != 1 {
+		t.Errorf("expected to stop after 1 match, got %d and %v", n, err)
+	}
+}
+
+func TestMatchReaderError(t *testing.T) {
+	tr := NewTrieBuilder().AddString("o").Build()
+	errRead := errors.New("read failed")
+
+	n := 0
+	r := io.MultiReader(strings.NewReader("Aho-Corasick"), iotest.ErrReader(errRead))
+	err := tr.MatchReader(r, func(m Match) bool {
+		n++
+		return true
+	})
+
+	if !errors.Is(err, errRead) || n != 2 {
+		t.Errorf("expected 2 matches and the read error, got %d and %v", n, err)
+	}
+}
+
+func TestReplacingReader(t *testing.T) {
+	input := contextInput()
+	replacements := [][]byte{[]byte("X"), nil, []byte("longer"), []byte("")}
+
+	for _, kind := range []MatchKind{StandardMatch, LeftmostLongest, LeftmostFirst} {
+		for _, tb := range []*TrieBuilder{
+			NewTrieBuilder(),
+			NewTrieBuilder().WordBoundary(UnicodeWordBoundary),
+		} {
+			tr := tb.
+				MatchKind(kind).
+				AddStrings([]string{"ab", "abcdabcdabcd", "bcd", "d a", "dabc"}).
+				Build()
+			expected := tr.ReplaceAll(input, replacements)
+
+			out, err := io.ReadAll(NewReplacingReader(bytes.NewReader(input), tr, replacements))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(out, expected) {
+				t.Errorf("kind %d: expected %d bytes, got %d", kind, len(expected), len(out))
+			}
+
+			r := iotest.OneByteReader(bytes.NewReader(input[:1000]))
+			out, err = io.ReadAll(iotest.HalfReader(NewReplacingReader(r, tr, replacements)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected := tr.ReplaceAll(input[:1000], replacements); !bytes.Equal(out, expected) {
+				t.Errorf("kind %d: expected %q reading a byte at a time, got %q", kind, expected, out)
+			}
+		}
+	}
+}
+
+func TestReplacingReaderError(t *testing.T) {
+	tr := NewTrieBuilder().AddStrings([]string{"Aho", "Corasick"}).Build()
+	errRead := errors.New("read failed")
+
+	r := io.MultiReader(strings.NewReader("Aho-Cora"), iotest.ErrReader(errRead))
+	out, err := io.ReadAll(NewReplacingReader(r, tr, [][]byte{[]byte("A")}))
+
+	// "Cora" might have been the start of a match.
+	if !errors.Is(err, errRead) || string(out) != "A-" {
+		t.Errorf("expected \"A-\" and the read error, got %q and %v", out, err)
+	}
+
+	// The same when the error comes along with the last bytes read.
+	r = &dataErrReader{[]byte("xxxxAho-Cora"), errRead}
+	out, err = io.ReadAll(NewReplacingReader(r, tr, [][]byte{[]byte("A")}))
+	if !errors.Is(err, errRead) || string(out) != "xxxxA-" {
+		t.Errorf("expected \"xxxxA-\" and the read error, got %q and %v", out, err)
+	}
+}
+
+// dataErrReader returns all of its data and err from the first read.
+type dataErrReader struct {
+	data []byte
+	err  error
+}
+
+func (r *dataErrReader) Read(p []byte) (int, error) {
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, r.err
+}
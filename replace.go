@@ -0,0 +1,139 @@
+package ahocorasick
+
+import (
+	"strings"
+)
+
+// spans returns the non-overlapping matches in input, as found by FindAll.
+func (tr *Trie) spans(input []byte) []span {
+	var spans []span
+	for from := 0; ; {
+		sp, ok := tr.find(input, from)
+		if !ok {
+			return spans
+		}
+		spans = append(spans, sp)
+		from = sp.pos + sp.n
+	}
+}
+
+// ReplaceAll returns a copy of input where the non-overlapping matches, as found by FindAll, are
+// replaced by the entry in replacements for their pattern. A nil entry, or a missing entry when
+// replacements is shorter than the number of patterns, leaves the matches of that pattern
+// unchanged. Use an empty, non-nil entry to remove them.
+func (tr *Trie) ReplaceAll(input []byte, replacements [][]byte) []byte {
+	spans := tr.spans(input)
+
+	size := len(input)
+	for _, sp := range spans {
+		if with := replacementFor(replacements, sp.pattern); with != nil {
+			size += len(with) - sp.n
+		}
+	}
+
+	out := make([]byte, 0, size)
+	last := 0
+	for _, sp := range spans {
+		if with := replacementFor(replacements, sp.pattern); with != nil {
+			out = append(out, input[last:sp.pos]...)
+			out = append(out, with...)
+			last = sp.pos + sp.n
+		}
+	}
+	return append(out, input[last:]...)
+}
+
+// ReplaceAllString is the same as ReplaceAll, but for strings. Since strings cannot be nil,
+// replacements is keyed by pattern number, and the matches of patterns without an entry are left
+// unchanged.
+func (tr *Trie) ReplaceAllString(input string, replacements map[uint32]string) string {
+	spans := tr.spans([]byte(input))
+
+	size := len(input)
+	replaced := false
+	for _, sp := range spans {
+		if with, ok := replacements[sp.pattern]; ok {
+			size += len(with) - sp.n
+			replaced = true
+		}
+	}
+	if !replaced {
+		return input
+	}
+
+	var b strings.Builder
+	b.Grow(size)
+	last := 0
+	for _, sp := range spans {
+		if with, ok := replacements[sp.pattern]; ok {
+			b.WriteString(input[last:sp.pos])
+			b.WriteString(with)
+			last = sp.pos + sp.n
+		}
+	}
+	b.WriteString(input[last:])
+	return b.String()
+}
+
+// ReplaceFunc returns a copy of input where the non-overlapping matches, as found by FindAll, are
+// replaced by the result of calling fn on them. The matches are passed in order, with the same
+// data as returned by Match. If fn returns nil, the match is left unchanged.
+func (tr *Trie) ReplaceFunc(input []byte, fn func(m Match) []byte) []byte {
+	spans := tr.spans(input)
+	with := make([][]byte, len(spans))
+
+	size := len(input)
+	for i, sp := range spans {
+		with[i] = fn(sp.match(input))
+		if with[i] != nil {
+			size += len(with[i]) - sp.n
+		}
+	}
+
+	out := make([]byte, 0, size)
+	last := 0
+	for i, sp := range spans {
+		if with[i] != nil {
+			out = append(out, input[last:sp.pos]...)
+			out = append(out, with[i]...)
+			last = sp.pos + sp.n
+		}
+	}
+	return append(out, input[last:]...)
+}
+
+// ReplaceFuncString is the same as ReplaceFunc, but for strings. To leave a match unchanged, fn
+// should return m.MatchString().
+func (tr *Trie) ReplaceFuncString(input string, fn func(m Match) string) string {
+	in := []byte(input)
+	spans := tr.spans(in)
+	if len(spans) == 0 {
+		return input
+	}
+
+	with := make([]string, len(spans))
+	size := len(input)
+	for i, sp := range spans {
+		with[i] = fn(sp.match(in))
+		size += len(with[i]) - sp.n
+	}
+
+	var b strings.Builder
+	b.Grow(size)
+	last := 0
+	for i, sp := range spans {
+		b.WriteString(input[last:sp.pos])
+		b.WriteString(with[i])
+		last = sp.pos + sp.n
+	}
+	b.WriteString(input[last:])
+	return b.String()
+}
+
+// replacementFor returns the replacement for pattern, or nil if it should be left unchanged.
+func replacementFor(replacements [][]byte, pattern uint32) []byte {
+	if int(pattern) < len(replacements) {
+		return replacements[pattern]
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package ahocorasick
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestReplaceAll(t *testing.T) {
+	cases := []struct {
+		name         string
+		kind         MatchKind
+		patterns     []string
+		replacements [][]byte
+		input        string
+		expected     string
+	}{
+		{
+			"Readme",
+			StandardMatch,
+			[]string{"or", "amet"},
+			[][]byte{[]byte("OR"), []byte("AMET")},
+			"Lorem ipsum dolor sit amet.",
+			"LORem ipsum dolOR sit AMET.",
+		},
+		{
+			"Identity",
+			StandardMatch,
+			[]string{"or", "amet"},
+			[][]byte{nil, []byte("[amet]")},
+			"Lorem ipsum dolor sit amet.",
+			"Lorem ipsum dolor sit [amet].",
+		},
+		{
+			"Missing",
+			StandardMatch,
+			[]string{"or", "amet"},
+			[][]byte{[]byte("0")},
+			"Lorem ipsum dolor sit amet.",
+			"L0em ipsum dol0 sit amet.",
+		},
+		{
+			"Remove",
+			StandardMatch,
+			[]string{" ", "."},
+			[][]byte{{}, {}},
+			"Lorem ipsum dolor sit amet.",
+			"Loremipsumdolorsitamet",
+		},
+		{
+			"LeftmostLongest",
+			LeftmostLongest,
+			[]string{"a", "ab", "abc"},
+			[][]byte{[]byte("1"), []byte("2"), []byte("3")},
+			"abcabxa",
+			"32x1",
+		},
+		{
+			"Swap",
+			LeftmostFirst,
+			[]string{"foo", "bar"},
+			[][]byte{[]byte("bar"), []byte("foo")},
+			"foobarfoo",
+			"barfoobar",
+		},
+		{
+			"NoMatch",
+			StandardMatch,
+			[]string{"Knuth"},
+			[][]byte{[]byte("Morris")},
+			"Aho-Corasick",
+			"Aho-Corasick",
+		},
+	}
+
+	for _, c := range cases {
+		tr := NewTrieBuilder().MatchKind(c.kind).AddStrings(c.patterns).Build()
+
+		if got := tr.ReplaceAll([]byte(c.input), c.replacements); string(got) != c.expected {
+			t.Errorf("%s: expected %q, got %q", c.name, c.expected, got)
+		}
+
+		// Nil entries are left out of the map, which leaves their patterns unchanged too.
+		replacements := make(map[uint32]string)
+		for i, r := range c.replacements {
+			if r != nil {
+				replacements[uint32(i)] = string(r)
+			}
+		}
+		if got := tr.ReplaceAllString(c.input, replacements); got != c.expected {
+			t.Errorf("%s: expected %q, got %q", c.name, c.expected, got)
+		}
+	}
+}
+
+func TestReplaceAllIdentityCaseInsensitive(t *testing.T) {
+	tr := NewTrieBuilder().
+		CaseInsensitive().
+		AddStrings([]string{"or", "amet"}).
+		Build()
+
+	input := "LOREM ipsum DoLoR sit AMET."
+	expected := "LOREM ipsum DoLoR sit [amet]."
+
+	if got := tr.ReplaceAll([]byte(input), [][]byte{nil, []byte("[amet]")}); string(got) != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+	if got := tr.ReplaceAllString(input, map[uint32]string{1: "[amet]"}); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestReplaceAllCopies(t *testing.T) {
+	tr := NewTrieBuilder().AddString("Knuth").Build()
+	input := []byte("Aho-Corasick")
+
+	output := tr.ReplaceAll(input, [][]byte{[]byte("Morris")})
+	output[0] = 'a'
+
+	if !bytes.Equal(input, []byte("Aho-Corasick")) {
+		t.Errorf("expected input to be left alone, got %q", input)
+	}
+}
+
+func TestReplaceFunc(t *testing.T) {
+	tr := NewTrieBuilder().
+		MatchKind(LeftmostLongest).
+		AddStrings([]string{"secret", "password", "pass"}).
+		Build()
+
+	input := "user=bob password=hunter2 secret=42 pass"
+	var matches []Match
+	mask := func(m Match) []byte {
+		matches = append(matches, m)
+		if m.Pattern() == 2 {
+			return nil
+		}
+		return bytes.Repeat([]byte("*"), len(m.Match()))
+	}
+
+	expected := "user=bob ********=hunter2 ******=42 pass"
+	if got := tr.ReplaceFunc([]byte(input), mask); string(got) != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	expectedMatches := []*Match{
+		newMatchString(9, 1, "password"),
+		newMatchString(26, 0, "secret"),
+		newMatchString(36, 2, "pass"),
+	}
+	if len(matches) != len(expectedMatches) {
+		t.Fatalf("expected %d matches, got %d", len(expectedMatches), len(matches))
+	}
+	for i := range matches {
+		if !MatchEqual(&matches[i], expectedMatches[i]) {
+			t.Errorf("expected %v, got %v", expectedMatches[i], &matches[i])
+		}
+	}
+
+	wrap := func(m Match) string {
+		return fmt.Sprintf("<%d:%s@%d>", m.Pattern(), m.Match(), m.Pos())
+	}
+	expected = "user=bob <1:password@9>=hunter2 <0:secret@26>=42 <2:pass@36>"
+	if got := tr.ReplaceFuncString(input, wrap); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
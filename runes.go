@@ -0,0 +1,79 @@
+package ahocorasick
+
+import (
+	"fmt"
+	"iter"
+	"unicode/utf8"
+)
+
+// RuneMatch is a Match that also knows its position and length in runes (characters), for
+// UTF-8 input.
+type RuneMatch struct {
+	Match
+	runePos int
+	runeLen int
+}
+
+func (m *RuneMatch) String() string {
+	return fmt.Sprintf("{%d %d %d %d %q}", m.pos, m.runePos, m.runeLen, m.pattern, m.match)
+}
+
+// RunePos returns the rune position of the match.
+func (m *RuneMatch) RunePos() int { return m.runePos }
+
+// RuneLen returns the number of runes in the match.
+func (m *RuneMatch) RuneLen() int { return m.runeLen }
+
+// AllRunes is the same as All, but the matches also carry their rune positions and lengths. The
+// runes are counted along with the search, so the input is only traversed once.
+func (tr *Trie) AllRunes(input []byte) iter.Seq[RuneMatch] {
+	return withRunes(input, tr.All(input))
+}
+
+// AllRunesString is the same as AllRunes, but for a string input.
+func (tr *Trie) AllRunesString(input string) iter.Seq[RuneMatch] {
+	return tr.AllRunes([]byte(input))
+}
+
+// FindAllRunes is the same as FindAllSeq, but the matches also carry their rune positions and
+// lengths.
+func (tr *Trie) FindAllRunes(input []byte) iter.Seq[RuneMatch] {
+	return withRunes(input, tr.FindAllSeq(input))
+}
+
+// FindAllRunesString is the same as FindAllRunes, but for a string input.
+func (tr *Trie) FindAllRunesString(input string) iter.Seq[RuneMatch] {
+	return tr.FindAllRunes([]byte(input))
+}
+
+// withRunes adds rune positions to matches, which must be ordered by their end position. Only
+// the input up to the end of the latest match is counted, so every byte is counted once.
+func withRunes(input []byte, matches iter.Seq[Match]) iter.Seq[RuneMatch] {
+	return func(yield func(RuneMatch) bool) {
+		counted := 0 // Bytes counted so far
+		runes := 0   // Runes in input[:counted]
+
+		for m := range matches {
+			end := int(m.pos) + len(m.match)
+			runes += countRunes(input[counted:end])
+			counted = end
+
+			n := countRunes(m.match)
+			if !yield(RuneMatch{Match: m, runePos: runes - n, runeLen: n}) {
+				return
+			}
+		}
+	}
+}
+
+// countRunes counts the runes in b by counting the bytes that are not UTF-8 continuation bytes.
+// For valid UTF-8 this is the same as utf8.RuneCount.
+func countRunes(b []byte) int {
+	n := 0
+	for _, c := range b {
+		if utf8.RuneStart(c) {
+			n++
+		}
+	}
+	return n
+}
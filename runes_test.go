@@ -0,0 +1,48 @@
+package ahocorasick
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestAllRunes(t *testing.T) {
+	tr := NewTrieBuilder().AddStrings([]string{"Ibsen", "Hedda Gabler", "for", "været"}).Build()
+	input := "«Hedda Gabler» av Henrik Ibsen – været for dårlig for Løvborg"
+
+	n := 0
+	for m := range tr.AllRunesString(input) {
+		n++
+		if want := utf8.RuneCountInString(input[:m.Pos()]); m.RunePos() != want {
+			t.Errorf("%v: expected rune position %d, got %d", &m, want, m.RunePos())
+		}
+		if want := utf8.RuneCount(m.Match.Match()); m.RuneLen() != want {
+			t.Errorf("%v: expected rune length %d, got %d", &m, want, m.RuneLen())
+		}
+		if got := string([]rune(input)[m.RunePos() : m.RunePos()+m.RuneLen()]); got != m.MatchString() {
+			t.Errorf("%v: expected runes to be %q, got %q", &m, m.MatchString(), got)
+		}
+	}
+
+	if n != 5 {
+		t.Errorf("expected 5 matches, got %d", n)
+	}
+}
+
+func TestFindAllRunes(t *testing.T) {
+	tr := NewTrieBuilder().MatchKind(LeftmostLongest).AddStrings([]string{"ø", "øl", "l"}).Build()
+
+	expected := [][2]int{{1, 1}, {2, 2}, {4, 1}, {6, 2}}
+	i := 0
+	for m := range tr.FindAllRunesString("æøøløæøl") {
+		if i >= len(expected) {
+			t.Fatalf("unexpected match %v", &m)
+		}
+		if m.RunePos() != expected[i][0] || m.RuneLen() != expected[i][1] {
+			t.Errorf("expected rune position %d and length %d, got %v", expected[i][0], expected[i][1], &m)
+		}
+		i++
+	}
+	if i != len(expected) {
+		t.Errorf("expected %d matches, got %d", len(expected), i)
+	}
+}
@@ -0,0 +1,199 @@
+package ahocorasick
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// sessionVersion is the version of the format written by Session.MarshalBinary.
+const sessionVersion uint8 = 1
+
+// ErrTrieMismatch is returned by Session.UnmarshalBinary if the state was saved by a session of
+// another Trie.
+var ErrTrieMismatch = errors.New("ahocorasick: session state belongs to another trie")
+
+// Session searches a stream that is written to it piece by piece, carrying the automaton on from
+// one Write to the next, so matches spanning several writes are found too. A Session only keeps
+// a few words of state, plus a short tail of the stream if the Trie has boundary constraints.
+// It must not be used by several goroutines at once, but any number of sessions can share a
+// Trie.
+type Session struct {
+	tr      *Trie
+	fn      WalkFn64
+	s       uint32
+	offset  int64  // Stream offset of the next byte written
+	tail    []byte // End of the stream, only kept if the Trie has boundary constraints
+	pending int    // Bytes at the end of tail not searched yet
+	stopped bool
+}
+
+// NewSession returns a Session calling fn on every match in the stream written to it, with end
+// positions that are offsets in the whole stream. Once fn returns false, no more matches are
+// reported until Reset is called.
+func (tr *Trie) NewSession(fn WalkFn64) *Session {
+	return &Session{tr: tr, fn: fn, s: rootState}
+}
+
+// Write searches p as the continuation of the stream written so far. It always returns len(p)
+// and a nil error.
+//
+// If the Trie has boundary constraints, whether a match is accepted may depend on the bytes
+// following it, so the matches ending in the last few bytes written are only reported by the
+// next Write or by Flush.
+func (ss *Session) Write(p []byte) (int, error) {
+	defer func() { ss.offset += int64(len(p)) }()
+	if ss.stopped {
+		return len(p), nil
+	}
+
+	if !ss.tr.constrained() {
+		ss.scan(p, 0, len(p), ss.offset)
+		return len(p), nil
+	}
+
+	back, delay := ss.tr.sessionTail()
+	if ss.tail == nil {
+		ss.tail = make([]byte, 0, 2*(back+delay))
+	}
+
+	// Search the start of p along with the tail, until the rest of p has all the context the
+	// boundary checks need.
+	n := min(len(p), back+delay)
+	from := len(ss.tail) - ss.pending
+	joint := append(ss.tail, p[:n]...)
+	to := max(from, len(joint)-delay)
+	if !ss.scan(joint, from, to, ss.offset-int64(len(ss.tail))) {
+		return len(p), nil
+	}
+
+	if n == len(p) {
+		ss.tail = joint[:copy(joint, joint[max(0, len(joint)-(back+delay)):])]
+		ss.pending = len(joint) - to
+		return len(p), nil
+	}
+
+	if !ss.scan(p, back, len(p)-delay, ss.offset) {
+		return len(p), nil
+	}
+	ss.tail = ss.tail[:copy(ss.tail[:back+delay], p[len(p)-(back+delay):])]
+	ss.pending = delay
+	return len(p), nil
+}
+
+// Flush reports the matches still waiting for more of the stream, taking the stream to end
+// here. It only does anything if the Trie has boundary constraints. Call Reset before writing
+// another stream.
+func (ss *Session) Flush() {
+	if ss.stopped || ss.pending == 0 {
+		return
+	}
+	ss.scan(ss.tail, len(ss.tail)-ss.pending, len(ss.tail), ss.offset-int64(len(ss.tail)))
+	ss.pending = 0
+}
+
+// Reset makes the Session start over with a new stream, as if it was just created.
+func (ss *Session) Reset() {
+	ss.s = rootState
+	ss.offset = 0
+	ss.tail = ss.tail[:0]
+	ss.pending = 0
+	ss.stopped = false
+}
+
+// MarshalBinary returns the state of the session: where the automaton is, the stream offset and
+// the tail of the stream kept for the boundary checks, along with the Fingerprint of the Trie.
+// Restoring it with UnmarshalBinary, in this or another process, lets the search go on from the
+// next byte of the stream. Flush should not have been called.
+func (ss *Session) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 0, 30+len(ss.tail))
+	data = append(data, sessionVersion)
+	data = binary.LittleEndian.AppendUint64(data, ss.tr.Fingerprint())
+	data = binary.LittleEndian.AppendUint32(data, ss.s)
+	data = binary.LittleEndian.AppendUint64(data, uint64(ss.offset))
+	data = binary.LittleEndian.AppendUint32(data, uint32(ss.pending))
+	data = append(data, boolByte(ss.stopped))
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(ss.tail)))
+	return append(data, ss.tail...), nil
+}
+
+// UnmarshalBinary restores a state returned by MarshalBinary. The session must belong to the
+// same Trie as the one that saved the state, or an equal one, e.g. loaded with Decode;
+// otherwise ErrTrieMismatch is returned. The callback of the session is kept.
+func (ss *Session) UnmarshalBinary(data []byte) error {
+	if len(data) < 30 {
+		return errors.New("ahocorasick: session state is too short")
+	}
+	if data[0] != sessionVersion {
+		return fmt.Errorf("ahocorasick: unknown session state version %d", data[0])
+	}
+	if binary.LittleEndian.Uint64(data[1:]) != ss.tr.Fingerprint() {
+		return ErrTrieMismatch
+	}
+
+	s := binary.LittleEndian.Uint32(data[9:])
+	offset := int64(binary.LittleEndian.Uint64(data[13:]))
+	pending := int(binary.LittleEndian.Uint32(data[21:]))
+	stopped := data[25] != 0
+	tail := data[30:]
+	if n := binary.LittleEndian.Uint32(data[26:]); uint64(n) != uint64(len(tail)) {
+		return errors.New("ahocorasick: session state has the wrong length")
+	}
+
+	back, delay := ss.tr.sessionTail()
+	if s == nilState || int(s) >= len(ss.tr.failTrans) || offset < 0 || pending > len(tail) ||
+		len(tail) > back+delay || !ss.tr.constrained() && len(tail) > 0 {
+		return errors.New("ahocorasick: invalid session state")
+	}
+
+	ss.s = s
+	ss.offset = offset
+	ss.pending = pending
+	ss.stopped = stopped
+	if ss.tr.constrained() {
+		ss.tail = append(make([]byte, 0, 2*(back+delay)), tail...)
+	}
+	return nil
+}
+
+// Offset returns the number of bytes written since the Session was created or last reset.
+func (ss *Session) Offset() int64 { return ss.offset }
+
+// scan searches input[from:to], where input starts at stream offset base, and reports whether
+// the session is still going.
+func (ss *Session) scan(input []byte, from, to int, base int64) bool {
+	var ok bool
+	ss.s, ok = ss.tr.scan(ss.s, input, from, to, ss.walk(base))
+	if !ok {
+		ss.stopped = true
+		ss.pending = 0
+	}
+	return ok
+}
+
+// walk returns a walkFn passing matches in an input starting at stream offset base to the
+// callback of the session.
+func (ss *Session) walk(base int64) walkFn {
+	return func(end int, n, pattern uint32) bool {
+		if !ss.fn(base+int64(end), n, pattern) {
+			ss.stopped = true
+			return false
+		}
+		return true
+	}
+}
+
+// sessionTail returns the number of bytes a session keeps before the unsearched part of the
+// stream (the longest match but its last byte, and a rune before it) and the number of bytes
+// after a match needed for the boundary checks.
+func (tr *Trie) sessionTail() (back, delay int) {
+	return max(0, tr.maxLen-1) + utf8.UTFMax, utf8.UTFMax
+}
+
+func boolByte(b bool) byte {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,174 @@
+package ahocorasick
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// sessionMatches writes input to a new session in pieces of size n and returns the matches.
+func sessionMatches(tr *Trie, input []byte, n int) []*Match {
+	var matches []Match
+	ss := tr.NewSession(func(end int64, n, pattern uint32) bool {
+		pos := end - int64(n) + 1
+		matches = append(matches, Match{pos: pos, pattern: pattern, match: input[pos : end+1]})
+		return true
+	})
+	for from := 0; from < len(input); from += n {
+		ss.Write(input[from:min(from+n, len(input))])
+	}
+	ss.Flush()
+	return pointers(matches)
+}
+
+func TestSession(t *testing.T) {
+	input := contextInput()[:5000]
+
+	trs := map[string]*Trie{
+		"plain": NewTrieBuilder().
+			AddStrings([]string{"ab", "abcdabcdabcd", "bcd", "d a", "dabc"}).
+			Build(),
+		"boundary": NewTrieBuilder().
+			WordBoundary(UnicodeWordBoundary).
+			AddStrings([]string{"ab", "abcd", "bcd", "d a", "dabc"}).
+			Build(),
+		"runes": NewTrieBuilder().
+			RuneBoundaries().
+			AddStrings([]string{"a", "b c", "dd"}).
+			Build(),
+	}
+
+	for name, tr := range trs {
+		expected := tr.Match(input)
+		for _, n := range []int{1, 2, 3, 7, 64, len(input)} {
+			if matches := sessionMatches(tr, input, n); !equalMatches(matches, expected) {
+				t.Errorf("%s: expected %d matches writing %d bytes at a time, got %d", name, len(expected), n, len(matches))
+			}
+		}
+	}
+}
+
+func TestSessionFlush(t *testing.T) {
+	tr := NewTrieBuilder().
+		WordBoundary(UnicodeWordBoundary).
+		AddStrings([]string{"ærlig", "lig"}).
+		Build()
+
+	var ends []int64
+	ss := tr.NewSession(func(end int64, n, pattern uint32) bool {
+		ends = append(ends, end)
+		return true
+	})
+
+	// The boundary after "ærlig" is only known once the stream ends.
+	ss.Write([]byte("u ærlig"))
+	if len(ends) != 0 {
+		t.Errorf("expected no matches before the end of the stream, got %v", ends)
+	}
+	ss.Flush()
+	if len(ends) != 1 || ends[0] != 7 {
+		t.Errorf("expected a match ending at 7, got %v", ends)
+	}
+
+	ends = nil
+	ss.Reset()
+	ss.Write([]byte("ærligé ærlig"))
+	ss.Flush()
+	if len(ends) != 1 || ends[0] != 14 || ss.Offset() != 15 {
+		t.Errorf("expected a match ending at 14 after 15 bytes, got %v after %d", ends, ss.Offset())
+	}
+}
+
+func TestSessionStop(t *testing.T) {
+	tr := NewTrieBuilder().AddString("o").Build()
+
+	n := 0
+	ss := tr.NewSession(func(end int64, _, _ uint32) bool {
+		n++
+		return false
+	})
+	ss.Write([]byte("Aho-Corasick"))
+	ss.Write([]byte("Aho-Corasick"))
+	if n != 1 {
+		t.Errorf("expected to stop after 1 match, got %d", n)
+	}
+
+	ss.Reset()
+	ss.Write([]byte("Aho-Corasick"))
+	if n != 2 || ss.Offset() != 12 {
+		t.Errorf("expected another match after reset, got %d matches after %d bytes", n, ss.Offset())
+	}
+}
+
+func TestSessionMarshalBinary(t *testing.T) {
+	input := contextInput()[:5000]
+
+	for _, tb := range []*TrieBuilder{
+		NewTrieBuilder(),
+		NewTrieBuilder().WordBoundary(UnicodeWordBoundary),
+	} {
+		tr := tb.AddStrings([]string{"ab", "abcd", "bcd", "d a", "dabc"}).Build()
+		expected := tr.Match(input)
+
+		var matches []Match
+		collect := func(input []byte) WalkFn64 {
+			return func(end int64, n, pattern uint32) bool {
+				pos := end - int64(n) + 1
+				matches = append(matches, Match{pos: pos, pattern: pattern, match: input[pos : end+1]})
+				return true
+			}
+		}
+
+		// Save the state in the middle of a match, and resume with a decoded copy of the trie.
+		ss := tr.NewSession(collect(input))
+		ss.Write(input[:2503])
+		state, err := ss.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		if err := Encode(&buf, tr); err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := Decode(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded.Fingerprint() != tr.Fingerprint() {
+			t.Errorf("expected the decoded trie to have fingerprint %x, got %x", tr.Fingerprint(), decoded.Fingerprint())
+		}
+
+		resumed := decoded.NewSession(collect(input))
+		if err := resumed.UnmarshalBinary(state); err != nil {
+			t.Fatal(err)
+		}
+		if resumed.Offset() != 2503 {
+			t.Errorf("expected to resume at offset 2503, got %d", resumed.Offset())
+		}
+		resumed.Write(input[2503:])
+		resumed.Flush()
+
+		if !equalMatches(pointers(matches), expected) {
+			t.Errorf("expected %d matches, got %d", len(expected), len(matches))
+		}
+	}
+}
+
+func TestSessionUnmarshalBinaryMismatch(t *testing.T) {
+	a := NewTrieBuilder().AddStrings([]string{"or", "amet"}).Build()
+	b := NewTrieBuilder().AddStrings([]string{"or", "amen"}).Build()
+
+	state, err := a.NewSession(func(int64, uint32, uint32) bool { return true }).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ss := b.NewSession(func(int64, uint32, uint32) bool { return true })
+	if err := ss.UnmarshalBinary(state); !errors.Is(err, ErrTrieMismatch) {
+		t.Errorf("expected ErrTrieMismatch, got %v", err)
+	}
+	if err := ss.UnmarshalBinary(state[:10]); err == nil {
+		t.Error("expected an error for a truncated state")
+	}
+}
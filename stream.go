@@ -3,10 +3,14 @@ package ahocorasick
 import (
 	"compress/gzip"
 	"encoding/binary"
+	"hash/fnv"
 	"io"
-	"sync"
 )
 
+// formatVersion is the version of the fields written after the original arrays. Files written
+// before these fields existed end right after the arrays and are read as version 0.
+const formatVersion uint32 = 5
+
 // Encode writes a Trie to w in gzip compressed binary format.
 func Encode(w io.Writer, trie *Trie) error {
 	enc := newEncoder(w)
@@ -32,7 +36,11 @@ func newEncoder(w io.Writer) *encoder {
 func (enc *encoder) encode(trie *Trie) error {
 	w := gzip.NewWriter(enc.w)
 	defer w.Close()
+	return writeTrie(w, trie)
+}
 
+// writeTrie writes the uncompressed fields of a Trie to w.
+func writeTrie(w io.Writer, trie *Trie) error {
 	// Write the lengths of all arrays first
 	if err := binary.Write(w, binary.LittleEndian, uint64(len(trie.dict))); err != nil {
 		return err
@@ -66,9 +74,44 @@ func (enc *encoder) encode(trie *Trie) error {
 		return err
 	}
 
+	// Write the versioned fields
+	if err := binary.Write(w, binary.LittleEndian, formatVersion); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, trie.kind); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint64(len(trie.boundary))); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, trie.boundary); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, trie.numPatterns); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, trie.runes); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, trie.endMode); err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// Fingerprint returns a hash of the Trie, which is the same for a Trie that was encoded and
+// decoded again, and almost certainly different for a Trie with other patterns or options. It
+// is computed on the first call.
+func (tr *Trie) Fingerprint() uint64 {
+	tr.fingerprintOnce.Do(func() {
+		h := fnv.New64a()
+		writeTrie(h, tr) // Writing to a hash never fails
+		tr.fingerprint = h.Sum64()
+	})
+	return tr.fingerprint
+}
+
 type decoder struct {
 	r io.Reader
 }
@@ -128,16 +171,72 @@ func (dec *decoder) decode() (*Trie, error) {
 		return nil, err
 	}
 
+	// Read the versioned fields, if any
+	var version uint32
+	if err := binary.Read(r, binary.LittleEndian, &version); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	var kind MatchKind
+	if version >= 1 {
+		if err := binary.Read(r, binary.LittleEndian, &kind); err != nil {
+			return nil, err
+		}
+	}
+
+	var boundary []Boundary
+	if version >= 2 {
+		var boundaryLen uint64
+		if err := binary.Read(r, binary.LittleEndian, &boundaryLen); err != nil {
+			return nil, err
+		}
+		if boundaryLen > 0 {
+			boundary = make([]Boundary, boundaryLen)
+			if err := binary.Read(r, binary.LittleEndian, boundary); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var numPatterns uint32
+	if version >= 3 {
+		if err := binary.Read(r, binary.LittleEndian, &numPatterns); err != nil {
+			return nil, err
+		}
+	} else {
+		// Older files do not store the number of patterns, so count those that can match.
+		for s, n := range dict {
+			if n > 0 && pattern[s] >= numPatterns {
+				numPatterns = pattern[s] + 1
+			}
+		}
+	}
+
+	var runes bool
+	if version >= 4 {
+		if err := binary.Read(r, binary.LittleEndian, &runes); err != nil {
+			return nil, err
+		}
+	}
+
+	var endMode EndMode
+	if version >= 5 {
+		if err := binary.Read(r, binary.LittleEndian, &endMode); err != nil {
+			return nil, err
+		}
+	}
+
 	return &Trie{
-		failTrans: failTrans,
-		dictLink:  dictLink,
-		dict:      dict,
-		pattern:   pattern,
-		matchPool: sync.Pool{
-			New: func() any { return &[]*Match{} },
-		},
-		matchStructPool: sync.Pool{
-			New: func() any { return new(Match) },
-		},
+		failTrans:   failTrans,
+		dictLink:    dictLink,
+		dict:        dict,
+		pattern:     pattern,
+		depth:       computeDepths(failTrans),
+		maxLen:      longestPattern(dict),
+		kind:        kind,
+		boundary:    boundary,
+		runes:       runes,
+		endMode:     endMode,
+		numPatterns: numPatterns,
 	}, nil
 }
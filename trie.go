@@ -16,72 +16,219 @@ type Trie struct {
 	dict     []uint32
 	pattern  []uint32
 	dictLink []uint32
+	depth    []uint32
+	maxLen   int // Length of the longest pattern
 
-	matchPool       sync.Pool // Pool for match slice pointers
-	matchStructPool sync.Pool // Pool for Match structs
+	kind        MatchKind
+	boundary    []Boundary // Boundary required around matches of each pattern (nil if none)
+	runes       bool       // Only report matches on UTF-8 rune boundaries
+	endMode     EndMode
+	numPatterns uint32
+
+	fingerprintOnce sync.Once
+	fingerprint     uint64
 }
 
+// NumPatterns returns the number of patterns added to the Trie. Patterns are numbered from 0 to
+// NumPatterns() - 1 in the order they were added.
+func (tr *Trie) NumPatterns() int { return int(tr.numPatterns) }
+
 // Walk calls this function on any match, giving the end position, length of the matched bytes,
 // and the pattern number.
 type WalkFn func(end, n, pattern uint32) bool
 
 // Walk runs the algorithm on a given output, calling the supplied callback function on every
 // match. The algorithm will terminate if the callback function returns false.
+//
+// The end position wraps around for inputs larger than 4 GiB; use Walk64 for those.
 func (tr *Trie) Walk(input []byte, fn WalkFn) {
+	tr.walk(input, 0, len(input), func(end int, n, pattern uint32) bool {
+		return fn(uint32(end), n, pattern)
+	})
+}
+
+// WalkFn64 is the same as WalkFn, but with a 64-bit end position.
+type WalkFn64 func(end int64, n, pattern uint32) bool
+
+// Walk64 is the same as Walk, but with 64-bit end positions, which are safe for inputs of any
+// size.
+func (tr *Trie) Walk64(input []byte, fn WalkFn64) {
+	tr.walk(input, 0, len(input), func(end int, n, pattern uint32) bool {
+		return fn(int64(end), n, pattern)
+	})
+}
+
+// walkFn is called by walk on every match, giving the end position in the input, the length of
+// the matched bytes, and the pattern number.
+type walkFn func(end int, n, pattern uint32) bool
+
+// walk runs the algorithm on input[from:to], calling fn on every match. Only matches within the
+// range are found, but accept sees the whole input, so boundaries take the bytes around the
+// range into account.
+func (tr *Trie) walk(input []byte, from, to int, fn walkFn) {
+	tr.scan(rootState, input, from, to, fn)
+}
+
+// scan is the same as walk, but starts in state s, which must be the state the automaton is in
+// after input[:from]. It returns the state after input[:to], and false if fn stopped the scan.
+func (tr *Trie) scan(s uint32, input []byte, from, to int, fn walkFn) (uint32, bool) {
 	// Local references to frequently accessed slices.
 	failTrans := tr.failTrans
 	dict := tr.dict
 	pattern := tr.pattern
 	dictLink := tr.dictLink
+	check := tr.constrained()
+	single := tr.endMode != AllAtEnd
 
-	s := rootState
-
-	inputLen := len(input)
-	for i := range inputLen {
+	for i := from; i < to; i++ {
 		s = failTrans[s][input[i]]
 
 		ds := dict[s]
 		dl := dictLink[s]
 		if ds != 0 || dl != nilState {
-			if ds != 0 && !fn(uint32(i), ds, pattern[s]) {
-				return
+			if single {
+				if u := tr.atEnd(input, i, s); u != nilState && !fn(i, dict[u], pattern[u]) {
+					return s, false
+				}
+				continue
+			}
+			if ds != 0 && (!check || tr.accept(input, i, ds, pattern[s])) &&
+				!fn(i, ds, pattern[s]) {
+				return s, false
 			}
 			for u := dl; u != nilState; u = dictLink[u] {
-				if !fn(uint32(i), dict[u], pattern[u]) {
-					return
+				if (!check || tr.accept(input, i, dict[u], pattern[u])) &&
+					!fn(i, dict[u], pattern[u]) {
+					return s, false
 				}
 			}
 		}
 	}
+
+	return s, true
+}
+
+// Count returns the number of matches in input, the same as len(tr.Match(input)), without
+// creating any matches.
+func (tr *Trie) Count(input []byte) int {
+	failTrans := tr.failTrans
+	dict := tr.dict
+	pattern := tr.pattern
+	dictLink := tr.dictLink
+	check := tr.constrained()
+	single := tr.endMode != AllAtEnd
+
+	count := 0
+	s := rootState
+	for i, c := range input {
+		s = failTrans[s][c]
+
+		if dict[s] == 0 && dictLink[s] == nilState {
+			continue
+		}
+		if single {
+			if tr.atEnd(input, i, s) != nilState {
+				count++
+			}
+			continue
+		}
+		for u := s; u != nilState; u = dictLink[u] {
+			if dict[u] != 0 && (!check || tr.accept(input, i, dict[u], pattern[u])) {
+				count++
+			}
+		}
+	}
+
+	return count
+}
+
+// CountString is the same as Count, but for a string input.
+func (tr *Trie) CountString(input string) int {
+	return tr.Count([]byte(input))
+}
+
+// CountByPattern adds the number of matches of every pattern in input to counts, which is
+// indexed by pattern number and must have room for at least NumPatterns() entries.
+func (tr *Trie) CountByPattern(input []byte, counts []uint32) {
+	failTrans := tr.failTrans
+	dict := tr.dict
+	pattern := tr.pattern
+	dictLink := tr.dictLink
+	check := tr.constrained()
+	single := tr.endMode != AllAtEnd
+
+	counts = counts[:tr.numPatterns]
+	s := rootState
+	for i, c := range input {
+		s = failTrans[s][c]
+
+		if dict[s] == 0 && dictLink[s] == nilState {
+			continue
+		}
+		if single {
+			if u := tr.atEnd(input, i, s); u != nilState {
+				counts[pattern[u]]++
+			}
+			continue
+		}
+		for u := s; u != nilState; u = dictLink[u] {
+			if dict[u] != 0 && (!check || tr.accept(input, i, dict[u], pattern[u])) {
+				counts[pattern[u]]++
+			}
+		}
+	}
+}
+
+// CountByPatternString is the same as CountByPattern, but for a string input.
+func (tr *Trie) CountByPatternString(input string, counts []uint32) {
+	tr.CountByPattern([]byte(input), counts)
 }
 
-// Match runs the Aho-Corasick string-search algorithm on a byte input.
+// Match runs the Aho-Corasick string-search algorithm on a byte input. The returned matches are
+// owned by the caller and are never reused by the Trie. Use AppendMatches to avoid allocating on
+// every call.
 func (tr *Trie) Match(input []byte) []*Match {
-	matches := tr.matchPool.Get().(*[]*Match)
-	*matches = (*matches)[:0] // Reset slice while keeping capacity
-
-	tr.Walk(input, func(end, n, pattern uint32) bool {
-		pos := end - n + 1
-		match := tr.matchStructPool.Get().(*Match)
-		match.pos = pos
-		match.pattern = pattern
-		match.match = input[pos : pos+n]
-		*matches = append(*matches, match)
-		return true
-	})
+	return pointers(tr.AppendMatches(nil, input))
+}
+
+// AppendMatches runs the Aho-Corasick string-search algorithm on a byte input, appending the
+// matches to dst and returning the extended slice. Reusing dst[:0] across calls (e.g. one buffer
+// per goroutine) avoids allocating once the buffer has grown large enough.
+func (tr *Trie) AppendMatches(dst []Match, input []byte) []Match {
+	return tr.appendMatches(dst, input, 0, len(input))
+}
+
+// AppendMatchesString is the same as AppendMatches, but for a string input.
+func (tr *Trie) AppendMatchesString(dst []Match, input string) []Match {
+	return tr.AppendMatches(dst, []byte(input))
+}
 
-	result := make([]*Match, len(*matches))
-	copy(result, *matches)
+// MatchRange is the same as Match, but only reports the matches lying entirely within
+// input[start:end]. Positions are relative to the whole input, and the bytes outside the range
+// still count when checking boundaries, e.g. a word that continues past the range is not a whole
+// word. It panics if the range is out of bounds, like input[start:end] would.
+func (tr *Trie) MatchRange(input []byte, start, end int) []*Match {
+	_ = input[start:end]
+	return pointers(tr.appendMatches(nil, input, start, end))
+}
 
-	return result
+// appendMatches appends the matches within input[from:to] to dst.
+func (tr *Trie) appendMatches(dst []Match, input []byte, from, to int) []Match {
+	tr.walk(input, from, to, func(end int, n, pattern uint32) bool {
+		pos := end - int(n) + 1
+		dst = append(dst, Match{pos: int64(pos), pattern: pattern, match: input[pos : end+1]})
+		return true
+	})
+	return dst
 }
 
-// MatchFirst is the same as Match, but returns after first successful match.
+// MatchFirst is the same as Match, but returns after first successful match. This is the match
+// that ends first, which is not necessarily the one that starts first; use FindFirst for that.
 func (tr *Trie) MatchFirst(input []byte) *Match {
 	var match *Match
-	tr.Walk(input, func(end, n, pattern uint32) bool {
-		pos := end - n + 1
-		match = &Match{pos: pos, match: input[pos : pos+n]}
+	tr.walk(input, 0, len(input), func(end int, n, pattern uint32) bool {
+		pos := end - int(n) + 1
+		match = &Match{pos: int64(pos), pattern: pattern, match: input[pos : end+1]}
 		return false
 	})
 	return match
@@ -97,11 +244,8 @@ func (tr *Trie) MatchFirstString(input string) *Match {
 	return tr.MatchFirst([]byte(input))
 }
 
-// ReleaseMatches returns the matches to the pool.
-func (tr *Trie) ReleaseMatches(matches []*Match) {
-	matchesPtr := &matches
-	for _, m := range *matchesPtr {
-		tr.matchStructPool.Put(m)
-	}
-	tr.matchPool.Put(matchesPtr)
-}
+// ReleaseMatches used to return matches to a pool shared by all callers, which made them unsafe
+// to use afterwards. Matches are no longer pooled and belong to the caller, so this does nothing.
+//
+// Deprecated: Use AppendMatches with a reused buffer instead.
+func (tr *Trie) ReleaseMatches(matches []*Match) {}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -174,6 +175,161 @@ func TestMatchFirst(t *testing.T) {
 	}
 }
 
+func TestAppendMatches(t *testing.T) {
+	tr := NewTrieBuilder().AddStrings([]string{"or", "amet"}).Build()
+	input := "Lorem ipsum dolor sit amet, consectetur adipiscing elit."
+	expected := []*Match{
+		newMatchString(1, 0, "or"),
+		newMatchString(15, 0, "or"),
+		newMatchString(22, 1, "amet"),
+	}
+
+	buf := tr.AppendMatchesString(nil, input)
+	first := &buf[0]
+
+	// Reusing the buffer must not allocate or disturb matches owned by other callers.
+	owned := tr.MatchString(input)
+	buf = tr.AppendMatchesString(buf[:0], input)
+
+	if &buf[0] != first {
+		t.Errorf("expected buffer to be reused")
+	}
+	if len(buf) != len(expected) {
+		t.Fatalf("expected %d matches, got %d", len(expected), len(buf))
+	}
+	for i := range buf {
+		if !MatchEqual(&buf[i], expected[i]) {
+			t.Errorf("expected %v, got %v", expected[i], &buf[i])
+		}
+		if !MatchEqual(owned[i], expected[i]) {
+			t.Errorf("expected %v, got %v", expected[i], owned[i])
+		}
+	}
+
+	in := []byte(input)
+	allocs := testing.AllocsPerRun(100, func() {
+		buf = tr.AppendMatches(buf[:0], in)
+	})
+	if allocs != 0 {
+		t.Errorf("expected no allocations, got %v", allocs)
+	}
+}
+
+func TestMatchRange(t *testing.T) {
+	input := []byte("name=dolor;text=Lorem ipsum dolor sit amet;tag=amet")
+
+	cases := []struct {
+		name       string
+		builder    *TrieBuilder
+		start, end int
+		expected   []*Match
+	}{
+		{
+			"Field",
+			NewTrieBuilder().AddStrings([]string{"or", "amet"}),
+			16, 43,
+			[]*Match{
+				newMatchString(17, 0, "or"),
+				newMatchString(31, 0, "or"),
+				newMatchString(38, 1, "amet"),
+			},
+		},
+		{
+			"Straddling",
+			NewTrieBuilder().AddStrings([]string{"ipsum", "name=dolor"}),
+			5, 24,
+			[]*Match{},
+		},
+		{
+			"Context",
+			NewTrieBuilder().WordBoundary(ASCIIWordBoundary).AddStrings([]string{"Lore", "dolor", "sit"}),
+			16, 35,
+			[]*Match{
+				newMatchString(28, 1, "dolor"),
+			},
+		},
+		{
+			"Empty",
+			NewTrieBuilder().AddStrings([]string{"or"}),
+			10, 10,
+			[]*Match{},
+		},
+	}
+
+	for _, c := range cases {
+		matches := c.builder.Build().MatchRange(input, c.start, c.end)
+
+		if len(matches) != len(c.expected) {
+			t.Errorf("%s: expected %d matches, got %d: %v", c.name, len(c.expected), len(matches), matches)
+			continue
+		}
+
+		for i := range matches {
+			if !MatchEqual(matches[i], c.expected[i]) {
+				t.Errorf("%s: expected %v, got %v", c.name, c.expected[i], matches[i])
+			}
+		}
+	}
+}
+
+func TestWalk64(t *testing.T) {
+	tr := NewTrieBuilder().AddStrings([]string{"or", "amet"}).Build()
+	input := []byte("Lorem ipsum dolor sit amet, consectetur adipiscing elit.")
+
+	var ends []int64
+	tr.Walk(input, func(end, n, pattern uint32) bool {
+		ends = append(ends, int64(end))
+		return true
+	})
+
+	i := 0
+	tr.Walk64(input, func(end int64, n, pattern uint32) bool {
+		if end != ends[i] {
+			t.Errorf("expected end %d, got %d", ends[i], end)
+		}
+		i++
+		return true
+	})
+	if i != len(ends) {
+		t.Errorf("expected %d matches, got %d", len(ends), i)
+	}
+
+	m := Match{pos: 5 << 30, pattern: 0, match: []byte("or")}
+	if m.Pos64() != 5<<30 || m.Pos() != 1<<30 {
+		t.Errorf("expected positions %d and %d, got %d and %d", int64(5<<30), 1<<30, m.Pos64(), m.Pos())
+	}
+}
+
+func TestCount(t *testing.T) {
+	tr := NewTrieBuilder().AddStrings([]string{"a", "ab", "bab", "bc", "bca", "c", "caa"}).Build()
+
+	if n := tr.CountString("abccab"); n != 7 {
+		t.Errorf("expected 7 matches, got %d", n)
+	}
+
+	counts := make([]uint32, tr.NumPatterns())
+	tr.CountByPatternString("abccab", counts)
+	tr.CountByPatternString("caa", counts)
+	expected := []uint32{4, 2, 0, 1, 0, 3, 1}
+	if !slices.Equal(counts, expected) {
+		t.Errorf("expected %v, got %v", expected, counts)
+	}
+
+	tr = NewTrieBuilder().WordBoundary(ASCIIWordBoundary).AddStrings([]string{"or", "amet"}).Build()
+	if n := tr.CountString("Lorem ipsum dolor sit amet or"); n != 2 {
+		t.Errorf("expected 2 matches, got %d", n)
+	}
+
+	input := []byte("Lorem ipsum dolor sit amet or")
+	allocs := testing.AllocsPerRun(100, func() {
+		tr.Count(input)
+		tr.CountByPattern(input, counts)
+	})
+	if allocs != 0 {
+		t.Errorf("expected no allocations, got %v", allocs)
+	}
+}
+
 func TestHedvig(t *testing.T) {
 	ibsen, err := ioutil.ReadFile("./test_data/Ibsen.txt")
 	if err != nil {
@@ -226,28 +382,26 @@ func BenchmarkMatchIbsen(b *testing.B) {
 
 	trie := NewTrieBuilder().AddStrings(patterns[:10000]).Build()
 
+	var matches []Match
+
 	b.Run("100", func(b *testing.B) {
 		for n := 0; n < b.N; n++ {
-			matches := trie.Match(ibsen[:100])
-			trie.ReleaseMatches(matches)
+			matches = trie.AppendMatches(matches[:0], ibsen[:100])
 		}
 	})
 	b.Run("1000", func(b *testing.B) {
 		for n := 0; n < b.N; n++ {
-			matches := trie.Match(ibsen[:1000])
-			trie.ReleaseMatches(matches)
+			matches = trie.AppendMatches(matches[:0], ibsen[:1000])
 		}
 	})
 	b.Run("10000", func(b *testing.B) {
 		for n := 0; n < b.N; n++ {
-			matches := trie.Match(ibsen[:10000])
-			trie.ReleaseMatches(matches)
+			matches = trie.AppendMatches(matches[:0], ibsen[:10000])
 		}
 	})
 	b.Run("100000", func(b *testing.B) {
 		for n := 0; n < b.N; n++ {
-			matches := trie.Match(ibsen[:100000])
-			trie.ReleaseMatches(matches)
+			matches = trie.AppendMatches(matches[:0], ibsen[:100000])
 		}
 	})
 }